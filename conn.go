@@ -2,7 +2,9 @@ package golangNeo4jBoltDriver
 
 import (
 	"bytes"
+	"context"
 	"database/sql/driver"
+	"encoding/binary"
 	"io/ioutil"
 	"net"
 	"time"
@@ -16,6 +18,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/encoding"
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
@@ -42,53 +45,148 @@ type Conn interface {
 	QueryNeo(query string, params map[string]interface{}) (Rows, error)
 	// QueryNeoAll queries using the neo4j-specific interface and returns all row data and output metadata
 	QueryNeoAll(query string, params map[string]interface{}) ([][]interface{}, map[string]interface{}, map[string]interface{}, error)
+	// QueryNeoBuffered queries using the neo4j-specific interface, reading up to
+	// threshold rows into memory.  If the whole result fits, the connection is freed
+	// for other queries while the rows are read. Otherwise the rest are streamed.
+	QueryNeoBuffered(query string, params map[string]interface{}, threshold int) (Rows, error)
+	// QueryNeoLimit queries using the neo4j-specific interface, returning at
+	// most limit.MaxRows rows.  The rows over the limit are discarded.
+	QueryNeoLimit(query string, params map[string]interface{}, limit RowLimit) (Rows, error)
 	// QueryPipeline queries using the neo4j-specific interface
 	// pipelining multiple statements
 	QueryPipeline(query []string, params ...map[string]interface{}) (PipelineRows, error)
+	// DescribeColumns gets the columns a query returns, without reading any of
+	// its rows. Bolt can't describe a query without running it, so the query IS
+	// executed, and any changes made by a non-read query are applied.
+	DescribeColumns(query string, params map[string]interface{}) ([]string, error)
+	// QueryScalar runs a query that returns exactly one row with one column,
+	// scanning the value into dest, converting between numeric types
+	QueryScalar(query string, params map[string]interface{}, dest interface{}) error
 	// ExecNeo executes a query using the neo4j-specific interface
 	ExecNeo(query string, params map[string]interface{}) (Result, error)
 	// ExecPipeline executes a query using the neo4j-specific interface
 	// pipelining multiple statements
 	ExecPipeline(query []string, params ...map[string]interface{}) ([]Result, error)
+	// BatchImport runs the statements in transactions, committing after every
+	// commitEvery statements.  progress, if given, is called after each commit.
+	// On error, the result has the number of statements that were committed.
+	BatchImport(statements []Statement, commitEvery int, progress func(ImportResult)) (ImportResult, error)
+	// UnwindBatched runs query once for each batch of up to batchSize items,
+	// passing the batch as the listParam parameter, and sums the counters.
+	// Each batch is committed separately, in order.
+	UnwindBatched(query string, listParam string, items []interface{}, batchSize int) (Counters, error)
+	// RunBatch pipelines the statements and reads all of their results into
+	// memory, returning their rows in order.  If a statement fails, the rows
+	// of the statements before it are returned with its error.
+	RunBatch(statements []Statement) ([]Rows, error)
+	// ExecuteRead runs work in a transaction, committing if it succeeds and
+	// rolling back if it returns an error.  Transient failures are retried.
+	ExecuteRead(ctx context.Context, work TxWork) (interface{}, error)
+	// ExecuteWrite runs work in a transaction, committing if it succeeds and
+	// rolling back if it returns an error.  Transient failures are retried.
+	ExecuteWrite(ctx context.Context, work TxWork) (interface{}, error)
 	// Close closes the connection
 	Close() error
 	// Begin starts a new transaction
 	Begin() (driver.Tx, error)
+	// InTransaction checks if the connection has an open transaction.  A
+	// transaction stays open after a query in it fails, until it is rolled back.
+	InTransaction() bool
 	// SetChunkSize is used to set the max chunk size of the
 	// bytes to send to Neo4j at once
 	SetChunkSize(uint16)
 	// SetTimeout sets the read/write timeouts for the
 	// connection to Neo4j
 	SetTimeout(time.Duration)
+	// SetDefaultParams sets parameters to send with every query on the
+	// connection.  Parameters given to a query override the defaults.
+	SetDefaultParams(map[string]interface{}) error
+	// SetRetryPredicate sets a function that ExecuteRead and ExecuteWrite
+	// consult, as well as the built in transient check, to decide if an
+	// error from a transaction should be retried.  nil removes it.
+	SetRetryPredicate(func(err error) bool)
+	// ServerTime gets the current time according to the Neo4j
+	// server. Useful for detecting clock skew with the server.
+	ServerTime() (time.Time, error)
+	// ID gets the id of the connection, which is included
+	// in all log lines for the connection
+	ID() string
 }
 
+// Statement is a query and its parameters
+type Statement struct {
+	Query  string
+	Params map[string]interface{}
+}
+
+// ImportResult is the progress of a BatchImport
+type ImportResult struct {
+	// Committed is the number of statements committed so far
+	Committed int
+	// Commits is the number of transactions committed so far
+	Commits int
+	// Counters is the sum of the counters of the committed statements
+	Counters Counters
+}
+
+// txControlQueries are the queries bolt v1 uses to control transactions,
+// which the statement prefix isn't added to
+var txControlQueries = map[string]bool{"BEGIN": true, "COMMIT": true, "ROLLBACK": true}
+
+// connCounter is used to give each connection a unique id
+var connCounter uint64
+
 type boltConn struct {
-	connStr       string
-	url           *url.URL
-	user          string
-	password      string
-	conn          net.Conn
-	serverVersion []byte
-	timeout       time.Duration
-	chunkSize     uint16
-	closed        bool
-	useTLS        bool
-	certFile      string
-	caCertFile    string
-	keyFile       string
-	tlsNoVerify   bool
-	transaction   *boltTx
-	statement     *boltStmt
-	driver        *boltDriver
-	poolDriver    DriverPool
+	id                uint64
+	name              string
+	connStr           string
+	url               *url.URL
+	user              string
+	password          string
+	conn              net.Conn
+	serverVersion     []byte
+	minVersion        uint32
+	timeout           time.Duration
+	handshakeTimeout  time.Duration
+	handshakeDeadline time.Time
+	connectAttempts   int
+	connectBackoff    time.Duration
+	txAttempts        int
+	txRetryBackoff    time.Duration
+	traceDumpLimit    int
+	dnsCacheTTL       time.Duration
+	localAddr         net.Addr
+	defaultParams     map[string]interface{}
+	retryPredicate    func(err error) bool
+	encodeStructs     bool
+	statementPrefix   string
+	errorOnUnknown    bool
+	chunkSize         uint16
+	closed            bool
+	broken            bool
+	useTLS            bool
+	certFile          string
+	caCertFile        string
+	keyFile           string
+	tlsNoVerify       bool
+	transaction       *boltTx
+	statement         *boltStmt
+	driver            *boltDriver
+	poolDriver        DriverPool
+	authTokens        *authTokenCache
 }
 
 func createBoltConn(connStr string) *boltConn {
 	return &boltConn{
-		connStr:       connStr,
-		timeout:       time.Second * time.Duration(60),
-		chunkSize:     math.MaxUint16,
-		serverVersion: make([]byte, 4),
+		id:              atomic.AddUint64(&connCounter, 1),
+		connStr:         connStr,
+		timeout:         time.Second * time.Duration(60),
+		connectAttempts: 1,
+		txAttempts:      3,
+		txRetryBackoff:  100 * time.Millisecond,
+		traceDumpLimit:  4096,
+		chunkSize:       math.MaxUint16,
+		serverVersion:   make([]byte, 4),
 	}
 }
 
@@ -97,6 +195,7 @@ func newBoltConn(connStr string, driver *boltDriver) (*boltConn, error) {
 
 	c := createBoltConn(connStr)
 	c.driver = driver
+	c.authTokens = driver.authTokens
 
 	err := c.initialize()
 	if err != nil {
@@ -111,6 +210,9 @@ func newPooledBoltConn(connStr string, driver DriverPool) (*boltConn, error) {
 
 	c := createBoltConn(connStr)
 	c.poolDriver = driver
+	if pool, ok := driver.(*boltDriverPool); ok {
+		c.authTokens = pool.authTokens
+	}
 
 	return c, nil
 }
@@ -144,6 +246,107 @@ func (c *boltConn) parseURL() (*url.URL, error) {
 		c.timeout = time.Duration(timeoutInt) * time.Second
 	}
 
+	handshakeTimeout := url.Query().Get("handshake_timeout")
+	if handshakeTimeout != "" {
+		handshakeTimeoutInt, err := strconv.Atoi(handshakeTimeout)
+		if err != nil {
+			return url, errors.New("Invalid format for handshake_timeout: %s.  Must be integer", handshakeTimeout)
+		}
+
+		c.handshakeTimeout = time.Duration(handshakeTimeoutInt) * time.Second
+	}
+
+	connectAttempts := url.Query().Get("connect_attempts")
+	if connectAttempts != "" {
+		connectAttemptsInt, err := strconv.Atoi(connectAttempts)
+		if err != nil || connectAttemptsInt < 1 {
+			return url, errors.New("Invalid format for connect_attempts: %s.  Must be integer greater than 0", connectAttempts)
+		}
+
+		c.connectAttempts = connectAttemptsInt
+	}
+
+	connectBackoff := url.Query().Get("connect_backoff_ms")
+	if connectBackoff != "" {
+		connectBackoffInt, err := strconv.Atoi(connectBackoff)
+		if err != nil {
+			return url, errors.New("Invalid format for connect_backoff_ms: %s.  Must be integer", connectBackoff)
+		}
+
+		c.connectBackoff = time.Duration(connectBackoffInt) * time.Millisecond
+	}
+
+	minVersion := url.Query().Get("min_protocol_version")
+	if minVersion != "" {
+		minVersionInt, err := strconv.ParseUint(minVersion, 10, 32)
+		if err != nil {
+			return url, errors.New("Invalid format for min_protocol_version: %s.  Must be integer", minVersion)
+		}
+
+		c.minVersion = uint32(minVersionInt)
+	}
+
+	txAttempts := url.Query().Get("tx_attempts")
+	if txAttempts != "" {
+		txAttemptsInt, err := strconv.Atoi(txAttempts)
+		if err != nil || txAttemptsInt < 1 {
+			return url, errors.New("Invalid format for tx_attempts: %s.  Must be integer greater than 0", txAttempts)
+		}
+
+		c.txAttempts = txAttemptsInt
+	}
+
+	txRetryBackoff := url.Query().Get("tx_retry_backoff_ms")
+	if txRetryBackoff != "" {
+		txRetryBackoffInt, err := strconv.Atoi(txRetryBackoff)
+		if err != nil {
+			return url, errors.New("Invalid format for tx_retry_backoff_ms: %s.  Must be integer", txRetryBackoff)
+		}
+
+		c.txRetryBackoff = time.Duration(txRetryBackoffInt) * time.Millisecond
+	}
+
+	traceDumpLimit := url.Query().Get("trace_dump_limit")
+	if traceDumpLimit != "" {
+		traceDumpLimitInt, err := strconv.Atoi(traceDumpLimit)
+		if err != nil {
+			return url, errors.New("Invalid format for trace_dump_limit: %s.  Must be integer", traceDumpLimit)
+		}
+
+		c.traceDumpLimit = traceDumpLimitInt
+	}
+
+	dnsCacheTTL := url.Query().Get("dns_cache_ttl")
+	if dnsCacheTTL != "" {
+		dnsCacheTTLInt, err := strconv.Atoi(dnsCacheTTL)
+		if err != nil {
+			return url, errors.New("Invalid format for dns_cache_ttl: %s.  Must be integer", dnsCacheTTL)
+		}
+
+		c.dnsCacheTTL = time.Duration(dnsCacheTTLInt) * time.Second
+	}
+
+	localAddr := url.Query().Get("local_addr")
+	if localAddr != "" {
+		if _, _, err := net.SplitHostPort(localAddr); err != nil {
+			localAddr = net.JoinHostPort(localAddr, "0")
+		}
+		c.localAddr, err = net.ResolveTCPAddr("tcp", localAddr)
+		if err != nil {
+			return url, errors.Wrap(err, "Invalid format for local_addr: %s.  Must be an IP address, optionally with a port", url.Query().Get("local_addr"))
+		}
+	}
+
+	c.name = url.Query().Get("conn_name")
+
+	encodeStructs := url.Query().Get("encode_structs")
+	c.encodeStructs = strings.HasPrefix(strings.ToLower(encodeStructs), "t") || encodeStructs == "1"
+
+	c.statementPrefix = url.Query().Get("statement_prefix")
+
+	errorOnUnknown := url.Query().Get("error_on_unknown_structure")
+	c.errorOnUnknown = strings.HasPrefix(strings.ToLower(errorOnUnknown), "t") || errorOnUnknown == "1"
+
 	useTLS := url.Query().Get("tls")
 	c.useTLS = strings.HasPrefix(strings.ToLower(useTLS), "t") || useTLS == "1"
 
@@ -155,15 +358,26 @@ func (c *boltConn) parseURL() (*url.URL, error) {
 		c.tlsNoVerify = strings.HasPrefix(strings.ToLower(noVerify), "t") || noVerify == "1"
 	}
 
-	log.Trace("Bolt Host: ", url.Host)
-	log.Trace("Timeout: ", c.timeout)
-	log.Trace("User: ", user)
-	log.Trace("Password: ", password)
-	log.Trace("TLS: ", c.useTLS)
-	log.Trace("TLS No Verify: ", c.tlsNoVerify)
-	log.Trace("Cert File: ", c.certFile)
-	log.Trace("Key File: ", c.keyFile)
-	log.Trace("CA Cert File: ", c.caCertFile)
+	log.Tracef("[%s] Bolt Host: %v", c.ID(), url.Host)
+	log.Tracef("[%s] Timeout: %v", c.ID(), c.timeout)
+	log.Tracef("[%s] Handshake Timeout: %v", c.ID(), c.handshakeTimeout)
+	log.Tracef("[%s] Connect Attempts: %v", c.ID(), c.connectAttempts)
+	log.Tracef("[%s] Connect Backoff: %v", c.ID(), c.connectBackoff)
+	log.Tracef("[%s] Min Protocol Version: %v", c.ID(), c.minVersion)
+	log.Tracef("[%s] Transaction Attempts: %v", c.ID(), c.txAttempts)
+	log.Tracef("[%s] Transaction Retry Backoff: %v", c.ID(), c.txRetryBackoff)
+	log.Tracef("[%s] DNS Cache TTL: %v", c.ID(), c.dnsCacheTTL)
+	log.Tracef("[%s] Local Address: %v", c.ID(), c.localAddr)
+	log.Tracef("[%s] User: %v", c.ID(), user)
+	log.Tracef("[%s] Password: %v", c.ID(), password)
+	log.Tracef("[%s] Encode Structs: %v", c.ID(), c.encodeStructs)
+	log.Tracef("[%s] Statement Prefix: %v", c.ID(), c.statementPrefix)
+	log.Tracef("[%s] Error On Unknown Structure: %v", c.ID(), c.errorOnUnknown)
+	log.Tracef("[%s] TLS: %v", c.ID(), c.useTLS)
+	log.Tracef("[%s] TLS No Verify: %v", c.ID(), c.tlsNoVerify)
+	log.Tracef("[%s] Cert File: %v", c.ID(), c.certFile)
+	log.Tracef("[%s] Key File: %v", c.ID(), c.keyFile)
+	log.Tracef("[%s] CA Cert File: %v", c.ID(), c.caCertFile)
 
 	return url, nil
 }
@@ -171,23 +385,36 @@ func (c *boltConn) parseURL() (*url.URL, error) {
 func (c *boltConn) createConn() (net.Conn, error) {
 
 	var err error
-	c.url, err = c.parseURL()
-	if err != nil {
-		return nil, errors.Wrap(err, "An error occurred parsing the conn URL")
+	var conn net.Conn
+
+	addr := c.url.Host
+	if c.dnsCacheTTL > 0 {
+		addr, err = dnsCache.resolve(c.url.Host, c.dnsCacheTTL)
+		if err != nil {
+			return nil, errors.Wrap(err, "An error occurred resolving neo4j host")
+		}
+		log.Tracef("[%s] Dialing resolved address: %s", c.ID(), addr)
+	}
+
+	dialer := &net.Dialer{}
+	if c.localAddr != nil {
+		dialer.LocalAddr = c.localAddr
 	}
 
-	var conn net.Conn
 	if c.useTLS {
 		config, err := c.tlsConfig()
 		if err != nil {
 			return nil, errors.Wrap(err, "An error occurred setting up TLS configuration")
 		}
-		conn, err = tls.Dial("tcp", c.url.Host, config)
+		// The certificate is for the host, not the address it resolved to
+		config.ServerName = c.url.Hostname()
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, config)
 		if err != nil {
 			return nil, errors.Wrap(err, "An error occurred dialing to neo4j")
 		}
 	} else {
-		conn, err = net.DialTimeout("tcp", c.url.Host, c.timeout)
+		dialer.Timeout = c.timeout
+		conn, err = dialer.Dial("tcp", addr)
 		if err != nil {
 			return nil, errors.Wrap(err, "An error occurred dialing to neo4j")
 		}
@@ -238,7 +465,7 @@ func (c *boltConn) handShake() error {
 
 	numWritten, err := c.Write(handShake)
 	if numWritten != 20 {
-		log.Errorf("Couldn't write expected bytes for magic preamble + supported versions. Written: %d. Expected: 4", numWritten)
+		log.Errorf("[%s] Couldn't write expected bytes for magic preamble + supported versions. Written: %d. Expected: 4", c.ID(), numWritten)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred writing magic preamble + supported versions")
 		}
@@ -247,77 +474,152 @@ func (c *boltConn) handShake() error {
 
 	numRead, err := c.Read(c.serverVersion)
 	if numRead != 4 {
-		log.Errorf("Could not read server version response. Read %d bytes. Expected 4 bytes. Output: %s", numRead, c.serverVersion)
+		log.Errorf("[%s] Could not read server version response. Read %d bytes. Expected 4 bytes. Output: %s", c.ID(), numRead, c.serverVersion)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred reading server version")
 		}
 		return err
 	} else if bytes.Equal(c.serverVersion, noVersionSupported) {
-		return errors.New("Server responded with no supported version")
+		return errors.NewProtocolVersionError(0, c.minVersion)
+	}
+
+	if version := binary.BigEndian.Uint32(c.serverVersion); version < c.minVersion {
+		return errors.NewProtocolVersionError(version, c.minVersion)
 	}
 
 	return nil
 }
 
 func (c *boltConn) initialize() error {
+	c.broken = false
 
 	// Handle recorder. If there is no conn string, assume we're playing back a recording.
 	// If there is a recorder and a conn string, assume we're recording the connection
 	// Else, just create the conn normally
-	var err error
 	if c.connStr == "" && c.driver != nil && c.driver.recorder != nil {
 		c.conn = c.driver.recorder
-	} else if c.driver != nil && c.driver.recorder != nil {
-		c.driver.recorder.Conn, err = c.createConn()
-		if err != nil {
+		return c.negotiate()
+	}
+
+	var err error
+	c.url, err = c.parseURL()
+	if err != nil {
+		return errors.Wrap(err, "An error occurred parsing the conn URL")
+	}
+
+	// Retry transient failures, such as the server still starting up,
+	// doubling the backoff after each attempt
+	backoff := c.connectBackoff
+	for attempt := 1; ; attempt++ {
+		err = c.connect()
+		if err == nil || attempt >= c.connectAttempts || !isRetryableConnectErr(err) {
 			return err
 		}
+
+		log.Infof("[%s] Connect attempt %d of %d failed. Retrying in %v: %s", c.ID(), attempt, c.connectAttempts, backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// connect dials the server and negotiates the connection
+func (c *boltConn) connect() error {
+	conn, err := c.createConn()
+	if err != nil {
+		return err
+	}
+
+	if c.driver != nil && c.driver.recorder != nil {
+		c.driver.recorder.Conn = conn
 		c.conn = c.driver.recorder
 	} else {
-		c.conn, err = c.createConn()
-		if err != nil {
-			return err
-		}
+		c.conn = conn
+	}
+
+	return c.negotiate()
+}
+
+// isRetryableConnectErr checks if an error connecting is worth retrying.
+// The server rejecting the credentials or the protocol version won't
+// change by trying again.
+func isRetryableConnectErr(err error) bool {
+	_, isAuth := errors.Cause(err).(*errors.AuthError)
+	return !isAuth && !errors.IsProtocolVersion(err)
+}
+
+// negotiate performs the handshake and INIT on the underlying connection,
+// bounded by the handshake timeout if one was given
+func (c *boltConn) negotiate() error {
+	if c.handshakeTimeout > 0 {
+		c.handshakeDeadline = time.Now().Add(c.handshakeTimeout)
+		defer func() { c.handshakeDeadline = time.Time{} }()
 	}
 
 	if err := c.handShake(); err != nil {
-		if e := c.Close(); e != nil {
-			log.Errorf("An error occurred closing connection: %s", e)
+		if e := c.conn.Close(); e != nil {
+			log.Errorf("[%s] An error occurred closing connection: %s", c.ID(), e)
 		}
-		return err
+		return c.handshakeErr(err)
 	}
 
 	respInt, err := c.sendInit()
 	if err != nil {
-		if e := c.Close(); e != nil {
-			log.Errorf("An error occurred closing connection: %s", e)
+		if e := c.conn.Close(); e != nil {
+			log.Errorf("[%s] An error occurred closing connection: %s", c.ID(), e)
 		}
-		return err
+		return c.handshakeErr(err)
 	}
 
 	switch resp := respInt.(type) {
 	case messages.SuccessMessage:
-		log.Infof("Successfully initiated Bolt connection: %+v", resp)
+		log.Infof("[%s] Successfully initiated Bolt connection: %+v", c.ID(), resp)
 		return nil
 	default:
-		log.Errorf("Got an unrecognized message when initializing connection :%+v", resp)
-		if e := c.Close(); e != nil {
-			log.Errorf("An error occurred closing connection: %s", e)
+		log.Errorf("[%s] Got an unrecognized message when initializing connection :%+v", c.ID(), resp)
+		if e := c.conn.Close(); e != nil {
+			log.Errorf("[%s] An error occurred closing connection: %s", c.ID(), e)
 		}
 		return errors.New("Unrecognized response from the server: %#v", resp)
 	}
 }
 
+// handshakeErr converts an error that occurred during the handshake
+// into a handshake timeout error if the handshake deadline has passed
+func (c *boltConn) handshakeErr(err error) error {
+	if err != nil && !c.handshakeDeadline.IsZero() && !time.Now().Before(c.handshakeDeadline) {
+		return errors.NewHandshakeTimeoutError(c.handshakeTimeout, err)
+	}
+	return err
+}
+
+// deadline gets the deadline for the next read or write to the stream
+func (c *boltConn) deadline() time.Time {
+	if !c.handshakeDeadline.IsZero() {
+		return c.handshakeDeadline
+	}
+	return time.Now().Add(c.timeout)
+}
+
+// ID gets the id of the connection, prefixed by the conn_name
+// given in the connection string if there is one
+func (c *boltConn) ID() string {
+	id := strconv.FormatUint(c.id, 10)
+	if c.name != "" {
+		return c.name + "-" + id
+	}
+	return id
+}
+
 // Read reads the data from the underlying connection
 func (c *boltConn) Read(b []byte) (n int, err error) {
-	if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+	if err := c.conn.SetReadDeadline(c.deadline()); err != nil {
 		return 0, errors.Wrap(err, "An error occurred setting read deadline")
 	}
 
 	n, err = c.conn.Read(b)
 
 	if log.GetLevel() >= log.TraceLevel {
-		log.Tracef("Read %d bytes from stream:\n\n%s\n", n, sprintByteHex(b))
+		log.Tracef("[%s] S->C Read %d bytes from stream:\n\n%s\n", c.ID(), n, sprintByteHexLimit(b[:n], c.traceDumpLimit))
 	}
 
 	if err != nil && err != io.EOF {
@@ -328,14 +630,18 @@ func (c *boltConn) Read(b []byte) (n int, err error) {
 
 // Write writes the data to the underlying connection
 func (c *boltConn) Write(b []byte) (n int, err error) {
-	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+	if c.broken {
+		return 0, errors.New("Connection is no longer usable after an unrecoverable error")
+	}
+
+	if err := c.conn.SetWriteDeadline(c.deadline()); err != nil {
 		return 0, errors.Wrap(err, "An error occurred setting write deadline")
 	}
 
 	n, err = c.conn.Write(b)
 
 	if log.GetLevel() >= log.TraceLevel {
-		log.Tracef("Wrote %d of %d bytes to stream:\n\n%s\n", len(b), n, sprintByteHex(b[:n]))
+		log.Tracef("[%s] C->S Wrote %d of %d bytes to stream:\n\n%s\n", c.ID(), n, len(b), sprintByteHexLimit(b[:n], c.traceDumpLimit))
 	}
 
 	if err != nil {
@@ -344,6 +650,34 @@ func (c *boltConn) Write(b []byte) (n int, err error) {
 	return n, err
 }
 
+// newEncoder makes an encoder for messages written to w,
+// with the encoding options of the connection
+func (c *boltConn) newEncoder(w io.Writer) encoding.Encoder {
+	encoder := encoding.NewEncoder(w, c.chunkSize)
+	encoder.EncodeStructs = c.encodeStructs
+	return encoder
+}
+
+func (c *boltConn) newDecoder(r io.Reader) encoding.Decoder {
+	decoder := encoding.NewDecoder(r)
+	decoder.ErrorOnUnknownStructure = c.errorOnUnknown
+	return decoder
+}
+
+// encode encodes a message to the stream.  The encoder writes each chunk
+// as it fills, so an error partway through a large message can leave the
+// start of it on the stream, where it would corrupt the next message.
+// If that happens the connection is marked broken, so it's not reused.
+func (c *boltConn) encode(msg interface{}) error {
+	w := &encoding.CountingWriter{W: c}
+	err := c.newEncoder(w).Encode(msg)
+	if err != nil && w.Count > 0 {
+		log.Errorf("[%s] Failed after writing part of a message. Connection is no longer usable: %s", c.ID(), err)
+		c.broken = true
+	}
+	return err
+}
+
 // Close closes the connection
 // Driver may allow for pooling in the future, keeping connections alive
 func (c *boltConn) Close() error {
@@ -352,6 +686,11 @@ func (c *boltConn) Close() error {
 		return nil
 	}
 
+	if c.broken {
+		// Nothing more can be sent to the server, so just drop the conn
+		return c.closeBroken()
+	}
+
 	if c.transaction != nil {
 		if err := c.transaction.Rollback(); err != nil {
 			return err
@@ -385,36 +724,58 @@ func (c *boltConn) Close() error {
 	return nil
 }
 
+// closeBroken closes a conn that the server closed unexpectedly,
+// or that was left with part of a message written.
+// Pooled conns are reclaimed without their net conn, so they
+// will be reconnected the next time they are opened.
+func (c *boltConn) closeBroken() error {
+	err := c.conn.Close()
+	c.endTransaction()
+	c.statement = nil
+
+	if c.poolDriver != nil {
+		c.conn = nil
+		c.poolDriver.reclaim(c)
+	} else {
+		c.closed = true
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "An error occurred closing the broken connection")
+	}
+	return nil
+}
+
 func (c *boltConn) ackFailure(failure messages.FailureMessage) error {
-	log.Infof("Acknowledging Failure: %#v", failure)
+	log.Infof("[%s] Acknowledging Failure: %#v", c.ID(), failure)
 
 	ack := messages.NewAckFailureMessage()
-	err := encoding.NewEncoder(c, c.chunkSize).Encode(ack)
+	err := c.encode(ack)
 	if err != nil {
 		return errors.Wrap(err, "An error occurred encoding ack failure message")
 	}
 
 	for {
-		respInt, err := encoding.NewDecoder(c).Decode()
+		respInt, err := c.newDecoder(c).Decode()
 		if err != nil {
 			return errors.Wrap(err, "An error occurred decoding ack failure message response")
 		}
 
 		switch resp := respInt.(type) {
 		case messages.IgnoredMessage:
-			log.Infof("Got ignored message when acking failure: %#v", resp)
+			log.Infof("[%s] Got ignored message when acking failure: %#v", c.ID(), resp)
 			continue
 		case messages.SuccessMessage:
-			log.Infof("Got success message when acking failure: %#v", resp)
+			log.Infof("[%s] Got success message when acking failure: %#v", c.ID(), resp)
 			return nil
 		case messages.FailureMessage:
-			log.Errorf("Got failure message when acking failure: %#v", resp)
+			log.Errorf("[%s] Got failure message when acking failure: %#v", c.ID(), resp)
 			return c.reset()
 		default:
-			log.Errorf("Got unrecognized response from acking failure: %#v", resp)
+			log.Errorf("[%s] Got unrecognized response from acking failure: %#v", c.ID(), resp)
 			err := c.Close()
 			if err != nil {
-				log.Errorf("An error occurred closing the session: %s", err)
+				log.Errorf("[%s] An error occurred closing the session: %s", c.ID(), err)
 			}
 			return errors.New("Got unrecognized response from acking failure: %#v. CLOSING SESSION!", resp)
 		}
@@ -422,39 +783,41 @@ func (c *boltConn) ackFailure(failure messages.FailureMessage) error {
 }
 
 func (c *boltConn) reset() error {
-	log.Info("Resetting session")
+	log.Infof("[%s] Resetting session", c.ID())
 
 	reset := messages.NewResetMessage()
-	err := encoding.NewEncoder(c, c.chunkSize).Encode(reset)
+	err := c.encode(reset)
 	if err != nil {
 		return errors.Wrap(err, "An error occurred encoding reset message")
 	}
 
 	for {
-		respInt, err := encoding.NewDecoder(c).Decode()
+		respInt, err := c.newDecoder(c).Decode()
 		if err != nil {
 			return errors.Wrap(err, "An error occurred decoding reset message response")
 		}
 
 		switch resp := respInt.(type) {
 		case messages.IgnoredMessage:
-			log.Infof("Got ignored message when resetting session: %#v", resp)
+			log.Infof("[%s] Got ignored message when resetting session: %#v", c.ID(), resp)
 			continue
 		case messages.SuccessMessage:
-			log.Infof("Got success message when resetting session: %#v", resp)
+			log.Infof("[%s] Got success message when resetting session: %#v", c.ID(), resp)
+			// Resetting rolls back any open transaction
+			c.endTransaction()
 			return nil
 		case messages.FailureMessage:
-			log.Errorf("Got failure message when resetting session: %#v", resp)
+			log.Errorf("[%s] Got failure message when resetting session: %#v", c.ID(), resp)
 			err = c.Close()
 			if err != nil {
-				log.Errorf("An error occurred closing the session: %s", err)
+				log.Errorf("[%s] An error occurred closing the session: %s", c.ID(), err)
 			}
 			return errors.New("Error resetting session: %#v. CLOSING SESSION!", resp)
 		default:
-			log.Errorf("Got unrecognized response from resetting session: %#v", resp)
+			log.Errorf("[%s] Got unrecognized response from resetting session: %#v", c.ID(), resp)
 			err = c.Close()
 			if err != nil {
-				log.Errorf("An error occurred closing the session: %s", err)
+				log.Errorf("[%s] An error occurred closing the session: %s", c.ID(), err)
 			}
 			return errors.New("Got unrecognized response from resetting session: %#v. CLOSING SESSION!", resp)
 		}
@@ -516,16 +879,32 @@ func (c *boltConn) Begin() (driver.Tx, error) {
 		return nil, errors.New("Unrecognized response type beginning transaction: %#v", success)
 	}
 
-	log.Infof("Got success message beginning transaction: %#v", success)
+	log.Infof("[%s] Got success message beginning transaction: %#v", c.ID(), success)
 
 	success, ok = pullInt.(messages.SuccessMessage)
 	if !ok {
 		return nil, errors.New("Unrecognized response type pulling transaction:  %#v", success)
 	}
 
-	log.Infof("Got success message pulling transaction: %#v", success)
+	log.Infof("[%s] Got success message pulling transaction: %#v", c.ID(), success)
 
-	return newTx(c), nil
+	c.transaction = newTx(c)
+	return c.transaction, nil
+}
+
+// InTransaction checks if the connection has an open transaction.  A
+// transaction stays open after a query in it fails, until it is rolled back.
+func (c *boltConn) InTransaction() bool {
+	return c.transaction != nil
+}
+
+// endTransaction closes the open transaction, if any, after the server
+// has ended it
+func (c *boltConn) endTransaction() {
+	if c.transaction != nil {
+		c.transaction.closed = true
+		c.transaction = nil
+	}
 }
 
 // Sets the size of the chunks to write to the stream
@@ -538,32 +917,126 @@ func (c *boltConn) SetTimeout(timeout time.Duration) {
 	c.timeout = timeout
 }
 
+// SetDefaultParams sets parameters to send with every query on the
+// connection.  Parameters given to a query override the defaults.
+// Errors if any of the defaults can't be encoded with the encoding options
+// of the connection.
+func (c *boltConn) SetDefaultParams(params map[string]interface{}) error {
+	if err := validateParams(c.newEncoder(ioutil.Discard), params); err != nil {
+		return errors.Wrap(err, "Invalid default parameters")
+	}
+
+	c.defaultParams = make(map[string]interface{}, len(params))
+	for k, v := range params {
+		c.defaultParams[k] = v
+	}
+	return nil
+}
+
+// SetRetryPredicate sets a function that ExecuteRead and ExecuteWrite
+// consult, as well as the built in transient check, to decide if an error
+// from a transaction should be retried.  Useful for errors that are
+// retryable in a deployment, such as from custom procedures.  nil removes it.
+func (c *boltConn) SetRetryPredicate(predicate func(err error) bool) {
+	c.retryPredicate = predicate
+}
+
+// newRunMessage makes a RUN message for the query, with the statement
+// prefix added and the default parameters merged into its args
+func (c *boltConn) newRunMessage(query string, args map[string]interface{}) messages.RunMessage {
+	if c.statementPrefix != "" && !txControlQueries[query] {
+		query = c.statementPrefix + " " + query
+	}
+
+	if len(c.defaultParams) == 0 {
+		if args == nil {
+			// Always send a map, even if no params were given
+			args = map[string]interface{}{}
+		}
+		return messages.NewRunMessage(query, args)
+	}
+
+	merged := make(map[string]interface{}, len(c.defaultParams)+len(args))
+	for k, v := range c.defaultParams {
+		merged[k] = v
+	}
+	for k, v := range args {
+		merged[k] = v
+	}
+	return messages.NewRunMessage(query, merged)
+}
+
+// ServerTime gets the current time according to the Neo4j server
+func (c *boltConn) ServerTime() (time.Time, error) {
+	data, _, _, err := c.QueryNeoAll("RETURN timestamp()", nil)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "An error occurred querying server time")
+	}
+
+	if len(data) != 1 || len(data[0]) != 1 {
+		return time.Time{}, errors.New("Unexpected response querying server time: %#v", data)
+	}
+
+	return parseServerTime(data[0][0])
+}
+
+// parseServerTime converts the result of a server time query into a time.Time.
+// Supports both timestamp() millisecond results and datetime() string results.
+func parseServerTime(val interface{}) (time.Time, error) {
+	switch val := val.(type) {
+	case int64:
+		return time.Unix(0, val*int64(time.Millisecond)), nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, val)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "An error occurred parsing server time: %s", val)
+		}
+		return t, nil
+	default:
+		return time.Time{}, errors.New("Unrecognized type for server time: %T %+v", val, val)
+	}
+}
+
 func (c *boltConn) consume() (interface{}, error) {
-	log.Info("Consuming response from bolt stream")
+	return c.consumeInto(nil)
+}
 
-	respInt, err := encoding.NewDecoder(c).Decode()
+// consumeInto consumes a response, decoding the fields of a record into
+// recordFields if it has the capacity for them
+func (c *boltConn) consumeInto(recordFields []interface{}) (interface{}, error) {
+	log.Infof("[%s] Consuming response from bolt stream", c.ID())
+
+	decoder := c.newDecoder(c)
+	decoder.RecordFields = recordFields
+	respInt, err := decoder.Decode()
 	if err != nil {
+		if errors.Cause(err) == io.ErrUnexpectedEOF {
+			log.Errorf("[%s] Server closed the connection unexpectedly. Connection is no longer usable", c.ID())
+			c.broken = true
+		}
 		return respInt, err
 	}
 
 	if log.GetLevel() >= log.TraceLevel {
-		log.Tracef("Consumed Response: %#v", respInt)
+		log.Tracef("[%s] Consumed Response: %#v", c.ID(), respInt)
 	}
 
 	if failure, isFail := respInt.(messages.FailureMessage); isFail {
-		log.Errorf("Got failure message: %#v", failure)
+		log.Errorf("[%s] Got failure message: %#v", c.ID(), failure)
 		err := c.ackFailure(failure)
 		if err != nil {
 			return nil, err
 		}
-		return failure, errors.New("Got failure message: %#v", failure)
+		code, _ := failure.Metadata["code"].(string)
+		message, _ := failure.Metadata["message"].(string)
+		return failure, errors.Wrap(errors.NewServerError(code, message), "Got failure message: %#v", failure)
 	}
 
 	return respInt, err
 }
 
 func (c *boltConn) consumeAll() ([]interface{}, interface{}, error) {
-	log.Info("Consuming all responses until success/failure")
+	log.Infof("[%s] Consuming all responses until success/failure", c.ID())
 
 	responses := []interface{}{}
 	for {
@@ -573,7 +1046,7 @@ func (c *boltConn) consumeAll() ([]interface{}, interface{}, error) {
 		}
 
 		if success, isSuccess := respInt.(messages.SuccessMessage); isSuccess {
-			log.Infof("Got success message: %#v", success)
+			log.Infof("[%s] Got success message: %#v", c.ID(), success)
 			return responses, success, nil
 		}
 
@@ -582,7 +1055,7 @@ func (c *boltConn) consumeAll() ([]interface{}, interface{}, error) {
 }
 
 func (c *boltConn) consumeAllMultiple(mult int) ([][]interface{}, []interface{}, error) {
-	log.Info("Consuming all responses %d times until success/failure", mult)
+	log.Infof("[%s] Consuming all responses %d times until success/failure", c.ID(), mult)
 
 	responses := make([][]interface{}, mult)
 	successes := make([]interface{}, mult)
@@ -601,20 +1074,57 @@ func (c *boltConn) consumeAllMultiple(mult int) ([][]interface{}, []interface{},
 }
 
 func (c *boltConn) sendInit() (interface{}, error) {
-	log.Infof("Sending INIT Message. ClientID: %s User: %s Password: %s", ClientID, c.user, c.password)
+	var initMessage messages.InitMessage
+	if c.authTokens != nil {
+		log.Infof("[%s] Sending INIT Message with provided auth token. ClientID: %s", c.ID(), ClientID)
+		ctx := context.Background()
+		if !c.handshakeDeadline.IsZero() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, c.handshakeDeadline)
+			defer cancel()
+		}
+		authToken, err := c.authTokens.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		initMessage = messages.NewInitMessageWithToken(ClientID, authToken)
+	} else {
+		log.Infof("[%s] Sending INIT Message. ClientID: %s User: %s Password: %v", c.ID(), ClientID, c.user, ParamRedactor("password", c.password))
+		initMessage = messages.NewInitMessage(ClientID, c.user, c.password)
+	}
 
-	initMessage := messages.NewInitMessage(ClientID, c.user, c.password)
-	if err := encoding.NewEncoder(c, c.chunkSize).Encode(initMessage); err != nil {
+	if err := c.encode(initMessage); err != nil {
 		return nil, errors.Wrap(err, "An error occurred sending init message")
 	}
 
-	return c.consume()
+	// The server may close the connection after a failed INIT, so
+	// don't try to acknowledge the failure like consume would.
+	respInt, err := c.newDecoder(c).Decode()
+	if err != nil {
+		return respInt, errors.Wrap(err, "An error occurred decoding init message response")
+	}
+
+	if failure, isFail := respInt.(messages.FailureMessage); isFail {
+		log.Errorf("[%s] Got failure message initializing connection: %#v", c.ID(), failure)
+		code, _ := failure.Metadata["code"].(string)
+		message, _ := failure.Metadata["message"].(string)
+		if strings.HasPrefix(code, "Neo.ClientError.Security.") {
+			if c.authTokens != nil {
+				// Get a fresh token from the provider on the next connection
+				c.authTokens.invalidate()
+			}
+			return failure, errors.NewAuthError(code, message)
+		}
+		return failure, errors.New("Got failure message: %#v", failure)
+	}
+
+	return respInt, nil
 }
 
 func (c *boltConn) sendRun(query string, args map[string]interface{}) error {
-	log.Infof("Sending RUN message: query %s (args: %#v)", query, args)
-	runMessage := messages.NewRunMessage(query, args)
-	if err := encoding.NewEncoder(c, c.chunkSize).Encode(runMessage); err != nil {
+	log.Infof("[%s] Sending RUN message: query %s (args: %#v)", c.ID(), query, redactParams(args))
+	runMessage := c.newRunMessage(query, args)
+	if err := c.encode(runMessage); err != nil {
 		return errors.Wrap(err, "An error occurred running query")
 	}
 
@@ -630,10 +1140,10 @@ func (c *boltConn) sendRunConsume(query string, args map[string]interface{}) (in
 }
 
 func (c *boltConn) sendPullAll() error {
-	log.Infof("Sending PULL_ALL message")
+	log.Infof("[%s] Sending PULL_ALL message", c.ID())
 
 	pullAllMessage := messages.NewPullAllMessage()
-	err := encoding.NewEncoder(c, c.chunkSize).Encode(pullAllMessage)
+	err := c.encode(pullAllMessage)
 	if err != nil {
 		return errors.Wrap(err, "An error occurred encoding pull all query")
 	}
@@ -649,13 +1159,31 @@ func (c *boltConn) sendPullAllConsume() (interface{}, error) {
 	return c.consume()
 }
 
-func (c *boltConn) sendRunPullAll(query string, args map[string]interface{}) error {
-	err := c.sendRun(query, args)
-	if err != nil {
-		return err
+// sendMessages encodes all of the given messages into a single buffer
+// and writes them to the stream at once, saving a write per message
+func (c *boltConn) sendMessages(msgs ...interface{}) error {
+	buf := &bytes.Buffer{}
+	for _, msg := range msgs {
+		if err := c.newEncoder(buf).Encode(msg); err != nil {
+			// The message isn't included, since it may hold sensitive params
+			return errors.Wrap(err, "An error occurred encoding %T message", msg)
+		}
+	}
+
+	if _, err := c.Write(buf.Bytes()); err != nil {
+		// Part of the messages may have been sent, so the
+		// connection can't be trusted to be in sync anymore
+		log.Errorf("[%s] Failed writing messages. Connection is no longer usable: %s", c.ID(), err)
+		c.broken = true
+		return errors.Wrap(err, "An error occurred writing messages")
 	}
 
-	return c.sendPullAll()
+	return nil
+}
+
+func (c *boltConn) sendRunPullAll(query string, args map[string]interface{}) error {
+	log.Infof("[%s] Sending RUN and PULL_ALL messages: query %s (args: %#v)", c.ID(), query, redactParams(args))
+	return c.sendMessages(c.newRunMessage(query, args), messages.NewPullAllMessage())
 }
 
 func (c *boltConn) sendRunPullAllConsumeRun(query string, args map[string]interface{}) (interface{}, error) {
@@ -698,10 +1226,10 @@ func (c *boltConn) sendRunPullAllConsumeAll(query string, args map[string]interf
 }
 
 func (c *boltConn) sendDiscardAll() error {
-	log.Infof("Sending DISCARD_ALL message")
+	log.Infof("[%s] Sending DISCARD_ALL message", c.ID())
 
 	discardAllMessage := messages.NewDiscardAllMessage()
-	err := encoding.NewEncoder(c, c.chunkSize).Encode(discardAllMessage)
+	err := c.encode(discardAllMessage)
 	if err != nil {
 		return errors.Wrap(err, "An error occurred encoding discard all query")
 	}
@@ -718,21 +1246,21 @@ func (c *boltConn) sendDiscardAllConsume() (interface{}, error) {
 }
 
 func (c *boltConn) sendRunDiscardAll(query string, args map[string]interface{}) error {
-	err := c.sendRun(query, args)
-	if err != nil {
-		return err
-	}
-
-	return c.sendDiscardAll()
+	log.Infof("[%s] Sending RUN and DISCARD_ALL messages: query %s (args: %#v)", c.ID(), query, redactParams(args))
+	return c.sendMessages(c.newRunMessage(query, args), messages.NewDiscardAllMessage())
 }
 
 func (c *boltConn) sendRunDiscardAllConsume(query string, args map[string]interface{}) (interface{}, interface{}, error) {
-	runResp, err := c.sendRunConsume(query, args)
+	if err := c.sendRunDiscardAll(query, args); err != nil {
+		return nil, nil, err
+	}
+
+	runResp, err := c.consume()
 	if err != nil {
 		return runResp, nil, err
 	}
 
-	discardResp, err := c.sendDiscardAllConsume()
+	discardResp, err := c.consume()
 	return runResp, discardResp, err
 }
 
@@ -759,6 +1287,40 @@ func (c *boltConn) QueryNeoAll(query string, params map[string]interface{}) ([][
 	return data, rows.metadata, metadata, err
 }
 
+func (c *boltConn) QueryNeoBuffered(query string, params map[string]interface{}, threshold int) (Rows, error) {
+	rows, err := c.queryNeo(query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rows.buffer(threshold); err != nil {
+		if e := rows.Close(); e != nil {
+			log.Errorf("[%s] An error occurred closing rows: %s", c.ID(), e)
+		}
+		return nil, errors.Wrap(err, "An error occurred buffering rows")
+	}
+
+	return rows, nil
+}
+
+// QueryNeoLimit queries using the neo4j-specific interface, returning at
+// most limit.MaxRows rows.  This protects against accidentally reading a huge
+// result, such as from a query missing a LIMIT.  Bolt v1 can't stop the server
+// sending the rest of the rows, so they are read and discarded, leaving the
+// connection usable.
+func (c *boltConn) QueryNeoLimit(query string, params map[string]interface{}, limit RowLimit) (Rows, error) {
+	if limit.MaxRows < 1 {
+		return nil, errors.New("Row limit must be 1 or more. Got: %d", limit.MaxRows)
+	}
+
+	rows, err := c.queryNeo(query, params)
+	if err != nil {
+		return nil, err
+	}
+	rows.limit = limit
+	return rows, nil
+}
+
 func (c *boltConn) queryNeo(query string, params map[string]interface{}) (*boltRows, error) {
 	if c.statement != nil {
 		return nil, errors.New("An open statement already exists")
@@ -819,6 +1381,64 @@ func (c *boltConn) Exec(query string, args []driver.Value) (driver.Result, error
 	return stmt.Exec(args)
 }
 
+// DescribeColumns gets the columns a query returns by running it and discarding
+// all of the rows.  Note that the query is executed, so non-read queries will
+// make their changes.
+func (c *boltConn) DescribeColumns(query string, params map[string]interface{}) ([]string, error) {
+	if c.statement != nil {
+		return nil, errors.New("An open statement already exists")
+	}
+	if c.closed {
+		return nil, errors.New("Connection already closed")
+	}
+
+	runResp, discardResp, err := c.sendRunDiscardAllConsume(query, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "An error occurred describing columns of query")
+	}
+
+	success, ok := runResp.(messages.SuccessMessage)
+	if !ok {
+		return nil, errors.New("Unrecognized response type running query: %#v", runResp)
+	}
+	if _, ok := discardResp.(messages.SuccessMessage); !ok {
+		return nil, errors.New("Unrecognized response type discarding all rows: %#v", discardResp)
+	}
+
+	return fieldsToStrings(success.Metadata), nil
+}
+
+// QueryScalar runs a query that returns exactly one row with one column,
+// scanning the value into dest, converting between numeric types.  It's an
+// error for the query to return any other number of rows or columns.
+func (c *boltConn) QueryScalar(query string, params map[string]interface{}, dest interface{}) error {
+	rows, err := c.queryNeo(query, params)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if columns := rows.Columns(); len(columns) != 1 {
+		return errors.New("Expected scalar query to return 1 column. Got: %d", len(columns))
+	}
+
+	row, _, err := rows.NextNeo()
+	if err == io.EOF {
+		return errors.New("Expected scalar query to return 1 row. Got: 0")
+	} else if err != nil {
+		return err
+	}
+
+	if _, _, err := rows.NextNeo(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return errors.New("Expected scalar query to return 1 row. Got more")
+	}
+
+	return Scanner{}.Scan(row, dest)
+}
+
 // ExecNeo executes a query that returns no rows. Implements a Neo-friendly alternative to sql/driver.
 func (c *boltConn) ExecNeo(query string, params map[string]interface{}) (Result, error) {
 	if c.statement != nil {
@@ -834,6 +1454,219 @@ func (c *boltConn) ExecNeo(query string, params map[string]interface{}) (Result,
 	return stmt.ExecNeo(params)
 }
 
+// BatchImport runs the statements in transactions, committing after every
+// commitEvery statements.  progress, if given, is called after each commit.
+// On error, the open transaction is rolled back, and the result has the
+// number of statements that were committed before it.
+func (c *boltConn) BatchImport(statements []Statement, commitEvery int, progress func(ImportResult)) (ImportResult, error) {
+	var result ImportResult
+	if commitEvery < 1 {
+		return result, errors.New("Must commit every 1 or more statements. Got: %d", commitEvery)
+	}
+
+	for start := 0; start < len(statements); start += commitEvery {
+		end := start + commitEvery
+		if end > len(statements) {
+			end = len(statements)
+		}
+
+		counters, err := c.importBatch(statements[start:end])
+		if err != nil {
+			return result, errors.Wrap(err, "An error occurred importing statements %d to %d. %d statements were committed", start, end-1, result.Committed)
+		}
+
+		result.Committed = end
+		result.Commits++
+		result.Counters = result.Counters.add(counters)
+		if progress != nil {
+			progress(result)
+		}
+	}
+
+	return result, nil
+}
+
+// importBatch runs the statements in a single transaction, returning the sum of their counters
+func (c *boltConn) importBatch(statements []Statement) (Counters, error) {
+	var counters Counters
+	tx, err := c.Begin()
+	if err != nil {
+		return counters, err
+	}
+
+	for _, statement := range statements {
+		result, err := c.ExecNeo(statement.Query, statement.Params)
+		if err != nil {
+			if e := tx.Rollback(); e != nil {
+				log.Errorf("[%s] An error occurred rolling back import batch: %s", c.ID(), e)
+			}
+			return counters, err
+		}
+
+		counters = counters.add(result.Counters())
+	}
+
+	return counters, tx.Commit()
+}
+
+// RunBatch pipelines the statements, sending all of their RUN and PULL_ALL
+// messages at once, then reads the results of each in order.  All of the
+// rows are read into memory, so the returned rows don't hold the connection
+// and can be read in any order.
+//
+// If a statement fails, the server ignores the statements after it.  The
+// error says which statement failed, and the rows of the statements before
+// it are returned.
+func (c *boltConn) RunBatch(statements []Statement) ([]Rows, error) {
+	if c.statement != nil {
+		return nil, errors.New("An open statement already exists")
+	}
+	if c.closed {
+		return nil, errors.New("Connection already closed")
+	}
+
+	msgs := make([]interface{}, 0, len(statements)*2)
+	for _, statement := range statements {
+		log.Infof("[%s] Sending RUN and PULL_ALL messages: query %s (args: %#v)", c.ID(), statement.Query, redactParams(statement.Params))
+		msgs = append(msgs, c.newRunMessage(statement.Query, statement.Params), messages.NewPullAllMessage())
+	}
+	if err := c.sendMessages(msgs...); err != nil {
+		return nil, errors.Wrap(err, "An error occurred sending batch")
+	}
+
+	results := make([]Rows, 0, len(statements))
+	for i, statement := range statements {
+		rows, err := c.readBatchRows(statement.Query)
+		if err != nil {
+			return results, errors.Wrap(err, "An error occurred running statement %d of batch: %s", i, statement.Query)
+		}
+		results = append(results, rows)
+	}
+	return results, nil
+}
+
+// readBatchRows reads the RUN response and all of the records for the next
+// statement of a batch
+func (c *boltConn) readBatchRows(query string) (*boltRows, error) {
+	stmt := newStmt(query, c)
+	defer stmt.Close()
+
+	runResp, err := c.consume()
+	if err != nil {
+		return nil, err
+	}
+	success, ok := runResp.(messages.SuccessMessage)
+	if !ok {
+		return nil, errors.New("Unrecognized response type running query: %#v", runResp)
+	}
+
+	rows := newQueryRows(stmt, success.Metadata)
+	for {
+		row, metadata, err := rows.nextNeo()
+		if err == io.EOF {
+			rows.eof = true
+			rows.finalMetadata = metadata
+			return rows, nil
+		} else if err != nil {
+			return nil, err
+		}
+		rows.buffered = append(rows.buffered, row)
+	}
+}
+
+// UnwindBatched runs query once for each batch of up to batchSize items,
+// passing the batch as the listParam parameter, and sums the counters of
+// each run.  This keeps messages for huge lists to a reasonable size.
+//
+// The batches are run in order, and unless a transaction is open, each
+// is committed on its own.  If a batch fails, the batches before it have
+// already been committed, and the returned counters are theirs.
+func (c *boltConn) UnwindBatched(query string, listParam string, items []interface{}, batchSize int) (Counters, error) {
+	var counters Counters
+	if batchSize < 1 {
+		return counters, errors.New("Batch size must be 1 or more. Got: %d", batchSize)
+	}
+
+	for start := 0; start < len(items); start += batchSize {
+		end := start + batchSize
+		if end > len(items) {
+			end = len(items)
+		}
+
+		result, err := c.ExecNeo(query, map[string]interface{}{listParam: items[start:end]})
+		if err != nil {
+			return counters, errors.Wrap(err, "An error occurred running batch of items %d to %d", start, end-1)
+		}
+		counters = counters.add(result.Counters())
+	}
+
+	return counters, nil
+}
+
+// ExecuteRead runs work in a transaction, committing if it succeeds and
+// rolling back if it returns an error.  Transient failures are retried.
+// Bolt v1 has no access modes, so this is the same as ExecuteWrite, but
+// marks the intent for code written against the official driver.
+func (c *boltConn) ExecuteRead(ctx context.Context, work TxWork) (interface{}, error) {
+	return c.executeTx(ctx, work)
+}
+
+// ExecuteWrite runs work in a transaction, committing if it succeeds and
+// rolling back if it returns an error.  Transient failures are retried.
+func (c *boltConn) ExecuteWrite(ctx context.Context, work TxWork) (interface{}, error) {
+	return c.executeTx(ctx, work)
+}
+
+// executeTx runs work in a transaction up to txAttempts times while it
+// fails with transient errors, doubling the backoff after each attempt
+func (c *boltConn) executeTx(ctx context.Context, work TxWork) (interface{}, error) {
+	backoff := c.txRetryBackoff
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, "Context done before running transaction")
+		}
+
+		result, err := c.runTx(work)
+		if err == nil || attempt >= c.txAttempts || !c.isRetryable(err) {
+			return result, err
+		}
+
+		log.Infof("[%s] Transaction attempt %d of %d failed. Retrying in %v: %s", c.ID(), attempt, c.txAttempts, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "Context done while waiting to retry transaction")
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryable checks if an error from a transaction should be retried
+func (c *boltConn) isRetryable(err error) bool {
+	return errors.IsTransient(err) || (c.retryPredicate != nil && c.retryPredicate(err))
+}
+
+// runTx runs work in a single transaction, rolling back if it fails
+func (c *boltConn) runTx(work TxWork) (interface{}, error) {
+	tx, err := c.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := work(c)
+	if err != nil {
+		if e := tx.Rollback(); e != nil {
+			log.Errorf("[%s] An error occurred rolling back transaction: %s", c.ID(), e)
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *boltConn) ExecPipeline(queries []string, params ...map[string]interface{}) ([]Result, error) {
 	if c.statement != nil {
 		return nil, errors.New("An open statement already exists")
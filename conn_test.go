@@ -1,11 +1,76 @@
 package golangNeo4jBoltDriver
 
 import (
+	"bytes"
+	"context"
+	stdErrors "errors"
 	"io"
+	stdlog "log"
+	"math"
+	"net"
+	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/encoding"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/log"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/messages"
 )
 
+// fakeConn is a fake net.Conn recording each write made to it
+// and replaying the data in reads
+type fakeConn struct {
+	net.Conn
+	reads     bytes.Buffer
+	writes    [][]byte
+	readDelay time.Duration
+	// writeErr, if set, fails writes after writing half of the bytes
+	writeErr error
+}
+
+// newFakeConn creates a fake conn that will replay the given messages on read
+func newFakeConn(t testing.TB, msgs ...interface{}) *fakeConn {
+	f := &fakeConn{}
+	for _, msg := range msgs {
+		b, err := encoding.Marshal(msg)
+		if err != nil {
+			t.Fatalf("An error occurred encoding fake message: %s", err)
+		}
+		f.reads.Write(b)
+	}
+	return f
+}
+
+func (f *fakeConn) Read(b []byte) (int, error) {
+	time.Sleep(f.readDelay)
+	return f.reads.Read(b)
+}
+
+func (f *fakeConn) Write(b []byte) (int, error) {
+	if f.writeErr != nil {
+		f.writes = append(f.writes, append([]byte{}, b[:len(b)/2]...))
+		return len(b) / 2, f.writeErr
+	}
+	f.writes = append(f.writes, append([]byte{}, b...))
+	return len(b), nil
+}
+
+func (f *fakeConn) Close() error {
+	return nil
+}
+
+func (f *fakeConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (f *fakeConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
 func TestBoltConn_parseURL(t *testing.T) {
 	c := &boltConn{connStr: "http://foo:7687"}
 
@@ -61,6 +126,1279 @@ func TestBoltConn_parseURL(t *testing.T) {
 	if c.keyFile != "key" {
 		t.Fatal("Expected key file 'key'")
 	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?handshake_timeout=5"}
+	_, err = c.parseURL()
+	if err != nil {
+		t.Fatal("Should not error on valid url")
+	}
+	if c.handshakeTimeout != 5*time.Second {
+		t.Fatal("Expected handshake timeout of 5 seconds")
+	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?handshake_timeout=foo"}
+	_, err = c.parseURL()
+	if err == nil {
+		t.Fatal("Expected error from invalid handshake timeout")
+	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?connect_attempts=3&connect_backoff_ms=100"}
+	_, err = c.parseURL()
+	if err != nil {
+		t.Fatal("Should not error on valid url")
+	}
+	if c.connectAttempts != 3 {
+		t.Fatal("Expected 3 connect attempts")
+	}
+	if c.connectBackoff != 100*time.Millisecond {
+		t.Fatal("Expected connect backoff of 100 milliseconds")
+	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?connect_attempts=0"}
+	_, err = c.parseURL()
+	if err == nil {
+		t.Fatal("Expected error from invalid connect attempts")
+	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?dns_cache_ttl=30"}
+	_, err = c.parseURL()
+	if err != nil {
+		t.Fatal("Should not error on valid url")
+	}
+	if c.dnsCacheTTL != 30*time.Second {
+		t.Fatal("Expected dns cache ttl of 30 seconds")
+	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?min_protocol_version=3"}
+	_, err = c.parseURL()
+	if err != nil {
+		t.Fatal("Should not error on valid url")
+	}
+	if c.minVersion != 3 {
+		t.Fatal("Expected min protocol version of 3")
+	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?local_addr=127.0.0.1"}
+	_, err = c.parseURL()
+	if err != nil {
+		t.Fatal("Should not error on valid url")
+	}
+	if c.localAddr.String() != "127.0.0.1:0" {
+		t.Fatalf("Unexpected local address: %s", c.localAddr)
+	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?local_addr=foo:bar"}
+	_, err = c.parseURL()
+	if err == nil {
+		t.Fatal("Expected error from invalid local address")
+	}
+}
+
+func TestBoltConn_HandshakeTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	// Server reads the handshake but stalls instead of responding
+	go func() {
+		b := make([]byte, len(handShake))
+		io.ReadFull(server, b)
+	}()
+
+	c := createBoltConn("")
+	c.conn = client
+	c.handshakeTimeout = 50 * time.Millisecond
+
+	done := make(chan error)
+	go func() { done <- c.negotiate() }()
+
+	select {
+	case err := <-done:
+		if !errors.IsHandshakeTimeout(err) {
+			t.Fatalf("Expected handshake timeout error. Got: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Handshake timeout never fired")
+	}
+
+	if !c.handshakeDeadline.IsZero() {
+		t.Fatal("Expected handshake deadline to be cleared after handshake")
+	}
+}
+
+func TestBoltConn_MinProtocolVersion(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t)
+	fake.reads.Write([]byte{0x00, 0x00, 0x00, 0x01})
+	c.conn = fake
+	c.minVersion = 1
+
+	if err := c.handShake(); err != nil {
+		t.Fatalf("An error occurred handshaking with the minimum version: %s", err)
+	}
+
+	c = createBoltConn("")
+	fake = newFakeConn(t)
+	fake.reads.Write([]byte{0x00, 0x00, 0x00, 0x01})
+	c.conn = fake
+	c.minVersion = 3
+
+	err := c.handShake()
+	if err == nil {
+		t.Fatal("Expected error handshaking with a version below the minimum")
+	}
+	if !strings.Contains(err.Error(), "version 3 or higher is required") {
+		t.Fatalf("Unexpected error for version below the minimum: %s", err)
+	}
+}
+
+func TestBoltConn_parseServerTime(t *testing.T) {
+	serverTime, err := parseServerTime(int64(1474329600123))
+	if err != nil {
+		t.Fatalf("Should not error on timestamp: %s", err)
+	}
+	if !serverTime.Equal(time.Date(2016, 9, 20, 0, 0, 0, 123000000, time.UTC)) {
+		t.Fatalf("Unexpected server time from timestamp: %s", serverTime)
+	}
+
+	serverTime, err = parseServerTime("2016-09-20T00:00:00.123+02:00")
+	if err != nil {
+		t.Fatalf("Should not error on datetime: %s", err)
+	}
+	if !serverTime.Equal(time.Date(2016, 9, 19, 22, 0, 0, 123000000, time.UTC)) {
+		t.Fatalf("Unexpected server time from datetime: %s", serverTime)
+	}
+
+	_, err = parseServerTime("not a time")
+	if err == nil {
+		t.Fatal("Expected error from invalid datetime")
+	}
+
+	_, err = parseServerTime(1.5)
+	if err == nil {
+		t.Fatal("Expected error from unrecognized type")
+	}
+}
+
+func TestBoltConn_sendRunDiscardAllSingleWrite(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"a"}}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+	c.conn = fake
+
+	columns, err := c.DescribeColumns("RETURN 1 AS a", nil)
+	if err != nil {
+		t.Fatalf("An error occurred describing columns: %s", err)
+	}
+	if !reflect.DeepEqual(columns, []string{"a"}) {
+		t.Fatalf("Unexpected columns: %#v", columns)
+	}
+
+	if len(fake.writes) != 1 {
+		t.Fatalf("Expected a single write for run + discard all. Got %d", len(fake.writes))
+	}
+	run, err := encoding.Marshal(messages.NewRunMessage("RETURN 1 AS a", map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("An error occurred encoding run message: %s", err)
+	}
+	discardAll, err := encoding.Marshal(messages.NewDiscardAllMessage())
+	if err != nil {
+		t.Fatalf("An error occurred encoding discard all message: %s", err)
+	}
+	if expected := append(run, discardAll...); !bytes.Equal(fake.writes[0], expected) {
+		t.Fatalf("Unexpected bytes written. Expected %x. Got: %x", expected, fake.writes[0])
+	}
+}
+
+func TestBoltConn_sendRunPullAllSingleWrite(t *testing.T) {
+	fake := &fakeConn{}
+	c := createBoltConn("")
+	c.conn = fake
+
+	params := map[string]interface{}{"a": int64(1)}
+	if err := c.sendRunPullAll("RETURN {a}", params); err != nil {
+		t.Fatalf("An error occurred sending run + pull all: %s", err)
+	}
+
+	if len(fake.writes) != 1 {
+		t.Fatalf("Expected a single write for run + pull all. Got %d", len(fake.writes))
+	}
+
+	run, err := encoding.Marshal(messages.NewRunMessage("RETURN {a}", params))
+	if err != nil {
+		t.Fatalf("An error occurred encoding run message: %s", err)
+	}
+	pullAll, err := encoding.Marshal(messages.NewPullAllMessage())
+	if err != nil {
+		t.Fatalf("An error occurred encoding pull all message: %s", err)
+	}
+
+	expected := append(run, pullAll...)
+	if !bytes.Equal(fake.writes[0], expected) {
+		t.Fatalf("Unexpected bytes written. Expected %x. Got: %x", expected, fake.writes[0])
+	}
+}
+
+type testParameterizer struct {
+	name string
+}
+
+func (p testParameterizer) Parameters() map[string]interface{} {
+	return map[string]interface{}{"name": p.name}
+}
+
+func TestBoltConn_sendRunParameterizer(t *testing.T) {
+	fake := &fakeConn{}
+	c := createBoltConn("")
+	c.conn = fake
+
+	err := c.sendRun("CREATE (n {props})", map[string]interface{}{"props": testParameterizer{name: "foo"}})
+	if err != nil {
+		t.Fatalf("An error occurred sending run: %s", err)
+	}
+
+	expected, err := encoding.Marshal(messages.NewRunMessage("CREATE (n {props})", map[string]interface{}{
+		"props": map[string]interface{}{"name": "foo"},
+	}))
+	if err != nil {
+		t.Fatalf("An error occurred encoding run message: %s", err)
+	}
+
+	if !bytes.Equal(bytes.Join(fake.writes, nil), expected) {
+		t.Fatalf("Expected Parameterizer map to be encoded. Expected %x. Got: %x", expected, bytes.Join(fake.writes, nil))
+	}
+}
+
+func TestBoltConn_sendRunNilParameterizer(t *testing.T) {
+	fake := &fakeConn{}
+	c := createBoltConn("")
+	c.conn = fake
+
+	err := c.sendRun("CREATE (n {props})", map[string]interface{}{"props": (*testParameterizer)(nil)})
+	if err != nil {
+		t.Fatalf("An error occurred sending run: %s", err)
+	}
+
+	expected, err := encoding.Marshal(messages.NewRunMessage("CREATE (n {props})", map[string]interface{}{"props": nil}))
+	if err != nil {
+		t.Fatalf("An error occurred encoding run message: %s", err)
+	}
+
+	if !bytes.Equal(bytes.Join(fake.writes, nil), expected) {
+		t.Fatalf("Expected nil Parameterizer to be encoded as nil. Expected %x. Got: %x", expected, bytes.Join(fake.writes, nil))
+	}
+
+	if _, err := encoding.AppendEncode(nil, (*testParameterizer)(nil)); err != nil {
+		t.Fatalf("An error occurred append encoding nil Parameterizer: %s", err)
+	}
+}
+
+func TestBoltConn_sendInitAuthFailure(t *testing.T) {
+	tests := []struct {
+		code           string
+		wrongCreds     bool
+		changePassword bool
+		locked         bool
+		tokenExpired   bool
+	}{
+		{errors.UnauthorizedCode, true, false, false, false},
+		{errors.CredentialsExpiredCode, false, true, false, false},
+		{errors.AuthenticationRateLimitCode, false, false, true, false},
+		{errors.TokenExpiredCode, false, false, false, true},
+	}
+
+	for _, test := range tests {
+		failure, err := encoding.Marshal(messages.NewFailureMessage(map[string]interface{}{
+			"code":    test.code,
+			"message": "auth failure",
+		}))
+		if err != nil {
+			t.Fatalf("An error occurred encoding failure message: %s", err)
+		}
+
+		fake := &fakeConn{}
+		fake.reads.Write(failure)
+		c := createBoltConn("")
+		c.conn = fake
+
+		_, err = c.sendInit()
+		authErr, ok := errors.Cause(err).(*errors.AuthError)
+		if !ok {
+			t.Fatalf("Expected auth error for code %s. Got: %#v", test.code, err)
+		}
+		if authErr.Code != test.code || authErr.Message != "auth failure" {
+			t.Fatalf("Unexpected auth error contents: %#v", authErr)
+		}
+		if authErr.IsWrongCredentials() != test.wrongCreds {
+			t.Fatalf("Unexpected IsWrongCredentials for code %s", test.code)
+		}
+		if authErr.IsPasswordChangeRequired() != test.changePassword {
+			t.Fatalf("Unexpected IsPasswordChangeRequired for code %s", test.code)
+		}
+		if authErr.IsAccountLocked() != test.locked {
+			t.Fatalf("Unexpected IsAccountLocked for code %s", test.code)
+		}
+		if authErr.IsTokenExpired() != test.tokenExpired {
+			t.Fatalf("Unexpected IsTokenExpired for code %s", test.code)
+		}
+	}
+}
+
+func TestBoltConn_QueryNeoBufferedSmall(t *testing.T) {
+	c := createBoltConn("")
+	c.conn = newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"n"}}),
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewRecordMessage([]interface{}{int64(2)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	rows, err := c.QueryNeoBuffered("UNWIND [1, 2] AS n RETURN n", nil, 5)
+	if err != nil {
+		t.Fatalf("An error occurred querying buffered: %s", err)
+	}
+
+	if c.statement != nil {
+		t.Fatal("Expected small result to release the connection")
+	}
+
+	data, metadata, err := rows.All()
+	if err != nil {
+		t.Fatalf("An error occurred reading buffered rows: %s", err)
+	}
+	if !reflect.DeepEqual(data, [][]interface{}{{int64(1)}, {int64(2)}}) {
+		t.Fatalf("Unexpected buffered rows: %#v", data)
+	}
+	if metadata["type"] != "r" {
+		t.Fatalf("Unexpected buffered rows metadata: %#v", metadata)
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatalf("An error occurred closing buffered rows: %s", err)
+	}
+}
+
+func TestBoltConn_QueryNeoBufferedLarge(t *testing.T) {
+	c := createBoltConn("")
+	c.conn = newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"n"}}),
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewRecordMessage([]interface{}{int64(2)}),
+		messages.NewRecordMessage([]interface{}{int64(3)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	rows, err := c.QueryNeoBuffered("UNWIND [1, 2, 3] AS n RETURN n", nil, 1)
+	if err != nil {
+		t.Fatalf("An error occurred querying buffered: %s", err)
+	}
+
+	if c.statement == nil {
+		t.Fatal("Expected large result to hold the connection while streaming")
+	}
+
+	data, _, err := rows.All()
+	if err != nil {
+		t.Fatalf("An error occurred reading streamed rows: %s", err)
+	}
+	if !reflect.DeepEqual(data, [][]interface{}{{int64(1)}, {int64(2)}, {int64(3)}}) {
+		t.Fatalf("Unexpected streamed rows: %#v", data)
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatalf("An error occurred closing streamed rows: %s", err)
+	}
+	if c.statement != nil {
+		t.Fatal("Expected closing rows to release the connection")
+	}
+}
+
+func TestBoltConn_ID(t *testing.T) {
+	c1 := createBoltConn("")
+	c2 := createBoltConn("")
+	if c1.ID() == c2.ID() {
+		t.Fatalf("Expected distinct connection ids. Got: %s and %s", c1.ID(), c2.ID())
+	}
+
+	c := createBoltConn("bolt://foo:7687?conn_name=reports")
+	if _, err := c.parseURL(); err != nil {
+		t.Fatalf("Should not error on valid url: %s", err)
+	}
+	if !strings.HasPrefix(c.ID(), "reports-") {
+		t.Fatalf("Expected connection id to be prefixed with conn_name. Got: %s", c.ID())
+	}
+
+	logged := &bytes.Buffer{}
+	infoLog := log.InfoLog
+	log.InfoLog = stdlog.New(logged, "", 0)
+	log.SetLevel("info")
+	defer func() {
+		log.InfoLog = infoLog
+		log.SetLevel(os.Getenv("BOLT_DRIVER_LOG"))
+	}()
+
+	c.conn = &fakeConn{}
+	if err := c.sendRun("RETURN 1", nil); err != nil {
+		t.Fatalf("An error occurred sending run: %s", err)
+	}
+	if !strings.Contains(logged.String(), "["+c.ID()+"]") {
+		t.Fatalf("Expected logs to include connection id %s. Got: %s", c.ID(), logged.String())
+	}
+}
+
+func TestBoltConn_TraceHexDump(t *testing.T) {
+	logged := &bytes.Buffer{}
+	traceLog := log.TraceLog
+	log.TraceLog = stdlog.New(logged, "", 0)
+	log.SetLevel("trace")
+	defer func() {
+		log.TraceLog = traceLog
+		log.SetLevel(os.Getenv("BOLT_DRIVER_LOG"))
+	}()
+
+	c := createBoltConn("")
+	c.conn = &fakeConn{}
+	if err := c.sendRun("RETURN 1", nil); err != nil {
+		t.Fatalf("An error occurred sending run: %s", err)
+	}
+
+	// The chunk body is the RUN struct with the query string
+	if !strings.Contains(logged.String(), "C->S Wrote 12 of 12 bytes") {
+		t.Fatalf("Expected direction and length of write in trace log. Got: %s", logged.String())
+	}
+	if !strings.Contains(logged.String(), "b2 10 88 52  45 54 55 52") {
+		t.Fatalf("Expected hex dump of RUN message in trace log. Got: %s", logged.String())
+	}
+
+	logged.Reset()
+	c.traceDumpLimit = 4
+	if err := c.sendRun("RETURN 1", nil); err != nil {
+		t.Fatalf("An error occurred sending run: %s", err)
+	}
+	if !strings.Contains(logged.String(), "... 8 more bytes") || strings.Contains(logged.String(), "45 54 55 52") {
+		t.Fatalf("Expected hex dump to be truncated to 4 bytes. Got: %s", logged.String())
+	}
+}
+
+func TestBoltConn_DescribeColumns(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"name", "age"}}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+	c.conn = fake
+
+	columns, err := c.DescribeColumns("MATCH (n) RETURN n.name AS name, n.age AS age", nil)
+	if err != nil {
+		t.Fatalf("An error occurred describing columns: %s", err)
+	}
+	if !reflect.DeepEqual(columns, []string{"name", "age"}) {
+		t.Fatalf("Unexpected columns: %#v", columns)
+	}
+
+	// The rows should be discarded, never pulled
+	written := bytes.Join(fake.writes, nil)
+	if !bytes.Contains(written, []byte{0xB0, messages.DiscardAllMessageSignature}) {
+		t.Fatalf("Expected DISCARD_ALL to be sent. Got: %#v", written)
+	}
+	if bytes.Contains(written, []byte{0xB0, messages.PullAllMessageSignature}) {
+		t.Fatalf("Expected PULL_ALL not to be sent. Got: %#v", written)
+	}
+	if fake.reads.Len() != 0 {
+		t.Fatal("Expected all responses to be consumed")
+	}
+}
+
+func TestBoltConn_SetDefaultParams(t *testing.T) {
+	c := createBoltConn("")
+	if err := c.SetDefaultParams(map[string]interface{}{"tenant": "a", "locale": "en"}); err != nil {
+		t.Fatalf("An error occurred setting default params: %s", err)
+	}
+
+	sentParams := func(args map[string]interface{}) map[string]interface{} {
+		fake := &fakeConn{}
+		c.conn = fake
+		if err := c.sendRun("RETURN $tenant, $locale", args); err != nil {
+			t.Fatalf("An error occurred sending run: %s", err)
+		}
+
+		decoded, err := encoding.Unmarshal(bytes.Join(fake.writes, nil))
+		if err != nil {
+			t.Fatalf("An error occurred decoding sent run: %s", err)
+		}
+		run, ok := decoded.(structures.GenericStructure)
+		if !ok || len(run.Fields) != 2 {
+			t.Fatalf("Expected RUN structure to be sent. Got: %#v", decoded)
+		}
+		params, _ := run.Fields[1].(map[string]interface{})
+		return params
+	}
+
+	expected := map[string]interface{}{"tenant": "a", "locale": "en"}
+	if params := sentParams(nil); !reflect.DeepEqual(params, expected) {
+		t.Fatalf("Expected default params to be sent. Expected %#v. Got: %#v", expected, params)
+	}
+
+	expected = map[string]interface{}{"tenant": "b", "locale": "en", "x": int64(1)}
+	if params := sentParams(map[string]interface{}{"tenant": "b", "x": 1}); !reflect.DeepEqual(params, expected) {
+		t.Fatalf("Expected query params to override defaults. Expected %#v. Got: %#v", expected, params)
+	}
+
+	if err := c.SetDefaultParams(map[string]interface{}{"bad": make(chan int)}); err == nil {
+		t.Fatal("Expected error setting unencodable default params")
+	}
+}
+
+func TestBoltConn_SetDefaultParamsEncodeStructs(t *testing.T) {
+	type tenant struct {
+		Name string `bolt:"name"`
+	}
+	defaults := map[string]interface{}{"tenant": tenant{Name: "a"}}
+
+	if err := createBoltConn("").SetDefaultParams(defaults); err == nil {
+		t.Fatal("Expected error setting struct default params without encode_structs")
+	}
+
+	c := &boltConn{connStr: "bolt://foo:7687?encode_structs=true", chunkSize: math.MaxUint16}
+	if _, err := c.parseURL(); err != nil {
+		t.Fatal("Should not error on valid url")
+	}
+	if err := c.SetDefaultParams(defaults); err != nil {
+		t.Fatalf("An error occurred setting struct default params with encode_structs: %s", err)
+	}
+}
+
+func TestBoltConn_RoundTripTime(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{}}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "w"}),
+	)
+	fake.readDelay = 10 * time.Millisecond
+	c.conn = fake
+
+	result, err := c.ExecNeo("CREATE (n)", nil)
+	if err != nil {
+		t.Fatalf("An error occurred executing query: %s", err)
+	}
+	if result.RoundTripTime() < fake.readDelay {
+		t.Fatalf("Expected round trip time of at least %v. Got: %v", fake.readDelay, result.RoundTripTime())
+	}
+}
+
+func TestBoltConn_BatchImport(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	created := messages.NewSuccessMessage(map[string]interface{}{"stats": map[string]interface{}{"nodes-created": int64(1)}})
+
+	// BEGIN, then each statement, then COMMIT, for batches of 10, 10 and 5
+	responses := []interface{}{}
+	for _, size := range []int{10, 10, 5} {
+		responses = append(responses, success, success)
+		for i := 0; i < size; i++ {
+			responses = append(responses, success, created)
+		}
+		responses = append(responses, success, success)
+	}
+
+	c := createBoltConn("")
+	fake := newFakeConn(t, responses...)
+	c.conn = fake
+
+	statements := make([]Statement, 25)
+	for i := range statements {
+		statements[i] = Statement{Query: "CREATE (n {i: $i})", Params: map[string]interface{}{"i": i}}
+	}
+
+	progress := []int{}
+	result, err := c.BatchImport(statements, 10, func(r ImportResult) {
+		progress = append(progress, r.Committed)
+	})
+	if err != nil {
+		t.Fatalf("An error occurred importing: %s", err)
+	}
+
+	if result.Committed != 25 || result.Commits != 3 || result.Counters.NodesCreated != 25 {
+		t.Fatalf("Unexpected import result: %#v", result)
+	}
+	if !reflect.DeepEqual(progress, []int{10, 20, 25}) {
+		t.Fatalf("Unexpected import progress: %#v", progress)
+	}
+	if commits := bytes.Count(bytes.Join(fake.writes, nil), []byte("COMMIT")); commits != 3 {
+		t.Fatalf("Expected 3 commits. Got: %d", commits)
+	}
+}
+
+func TestBoltConn_BatchImportFailure(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	failure := messages.NewFailureMessage(map[string]interface{}{"code": "Neo.ClientError.Statement.SyntaxError"})
+
+	// First batch of 2 commits, then the first statement of the second fails,
+	// which is acked, and the transaction rolled back
+	c := createBoltConn("")
+	c.conn = newFakeConn(t,
+		success, success,
+		success, success,
+		success, success,
+		success, success,
+		success, success,
+		failure, success,
+		success, success,
+	)
+
+	statements := make([]Statement, 4)
+	for i := range statements {
+		statements[i] = Statement{Query: "CREATE (n)"}
+	}
+
+	result, err := c.BatchImport(statements, 2, nil)
+	if err == nil {
+		t.Fatal("Expected error importing with a failing statement")
+	}
+	if result.Committed != 2 {
+		t.Fatalf("Expected 2 statements to be committed. Got: %d", result.Committed)
+	}
+	if !strings.Contains(err.Error(), "2 statements were committed") {
+		t.Fatalf("Expected error to report the committed statements. Got: %s", err)
+	}
+}
+
+func TestBoltConn_NilParams(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"n"}}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+	c.conn = fake
+
+	rows, err := c.QueryNeo("RETURN 1", nil)
+	if err != nil {
+		t.Fatalf("An error occurred querying with nil params: %s", err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("An error occurred closing rows: %s", err)
+	}
+
+	expected, err := encoding.Marshal(messages.NewRunMessage("RETURN 1", map[string]interface{}{}))
+	if err != nil {
+		t.Fatalf("An error occurred encoding expected run message: %s", err)
+	}
+	if !bytes.HasPrefix(bytes.Join(fake.writes, nil), expected) {
+		t.Fatalf("Expected nil params to be sent as an empty map. Expected %x. Got: %x", expected, bytes.Join(fake.writes, nil))
+	}
+}
+
+func TestBoltConn_ErrorOnUnknownStructure(t *testing.T) {
+	unknown := structures.GenericStructure{StructSignature: 0x7A, Fields: []interface{}{int64(1)}}
+	query := func(connStr string) ([][]interface{}, error) {
+		c := createBoltConn(connStr)
+		if _, err := c.parseURL(); err != nil {
+			t.Fatalf("Should not error on valid url: %s", err)
+		}
+		c.conn = newFakeConn(t,
+			messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"n"}}),
+			messages.NewRecordMessage([]interface{}{unknown}),
+			messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+		)
+
+		data, _, _, err := c.QueryNeoAll("RETURN n", nil)
+		return data, err
+	}
+
+	data, err := query("bolt://foo:7687")
+	if err != nil {
+		t.Fatalf("An error occurred querying unknown structure: %s", err)
+	}
+	if !reflect.DeepEqual(data, [][]interface{}{{unknown}}) {
+		t.Fatalf("Expected unknown structure placeholder by default. Got: %#v", data)
+	}
+
+	if _, err := query("bolt://foo:7687?error_on_unknown_structure=true"); err == nil || !strings.Contains(err.Error(), "Unknown structure signature: 7a") {
+		t.Fatalf("Expected unknown structure error with error_on_unknown_structure. Got: %v", err)
+	}
+}
+
+func TestBoltConn_StatementPrefix(t *testing.T) {
+	c := createBoltConn("bolt://foo:7687?statement_prefix=CYPHER+runtime%3Dslotted")
+	if _, err := c.parseURL(); err != nil {
+		t.Fatalf("Should not error on valid url: %s", err)
+	}
+
+	tests := map[string]string{
+		"MATCH (n) RETURN n": "CYPHER runtime=slotted MATCH (n) RETURN n",
+		"BEGIN":              "BEGIN",
+		"COMMIT":             "COMMIT",
+		"ROLLBACK":           "ROLLBACK",
+	}
+	for query, expected := range tests {
+		if run := c.newRunMessage(query, nil); run.AllFields()[0] != expected {
+			t.Fatalf("Unexpected statement for %s. Expected %q. Got: %q", query, expected, run.AllFields()[0])
+		}
+	}
+
+	c = createBoltConn("")
+	if run := c.newRunMessage("MATCH (n) RETURN n", nil); run.AllFields()[0] != "MATCH (n) RETURN n" {
+		t.Fatalf("Expected no prefix by default. Got: %q", run.AllFields()[0])
+	}
+}
+
+func TestBoltConn_EncodeStructs(t *testing.T) {
+	type person struct {
+		Name string `bolt:"name"`
+	}
+
+	c := createBoltConn("")
+	c.conn = newFakeConn(t)
+	if err := c.sendRun("CREATE (n {props})", map[string]interface{}{"props": person{Name: "foo"}}); err == nil {
+		t.Fatal("Expected error sending struct parameter without encode_structs")
+	}
+
+	c = &boltConn{connStr: "bolt://foo:7687?encode_structs=true", chunkSize: math.MaxUint16}
+	if _, err := c.parseURL(); err != nil {
+		t.Fatal("Should not error on valid url")
+	}
+	fake := newFakeConn(t)
+	c.conn = fake
+	if err := c.sendRun("CREATE (n {props})", map[string]interface{}{"props": person{Name: "foo"}}); err != nil {
+		t.Fatalf("An error occurred sending struct parameter: %s", err)
+	}
+
+	expected, err := encoding.Marshal(messages.NewRunMessage("CREATE (n {props})", map[string]interface{}{
+		"props": map[string]interface{}{"name": "foo"},
+	}))
+	if err != nil {
+		t.Fatalf("An error occurred encoding expected run message: %s", err)
+	}
+	if !bytes.Equal(bytes.Join(fake.writes, nil), expected) {
+		t.Fatalf("Expected struct to be sent as a map. Got: %#v", fake.writes)
+	}
+}
+
+func TestBoltConn_PartialEncodeFailure(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t)
+	c.conn = fake
+	c.chunkSize = 16
+
+	// The string fills a chunk, which is written before the channel fails to encode
+	params := map[string]interface{}{"list": []interface{}{strings.Repeat("a", 32), make(chan int)}}
+	if err := c.sendRun("RETURN {list}", params); err == nil {
+		t.Fatal("Expected error encoding unsupported parameter")
+	}
+	if len(fake.writes) == 0 {
+		t.Fatal("Expected part of the message to have been written")
+	}
+	if !c.broken {
+		t.Fatal("Expected connection to be broken after a partial write")
+	}
+
+	writes := len(fake.writes)
+	if _, err := c.ExecNeo("RETURN 1", nil); err == nil {
+		t.Fatal("Expected error reusing broken connection")
+	}
+	if len(fake.writes) != writes {
+		t.Fatal("Expected nothing to be written to broken connection")
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("An error occurred closing broken connection: %s", err)
+	}
+	if !c.closed {
+		t.Fatal("Expected broken connection to be closed")
+	}
+}
+
+func TestBoltConn_sendMessagesWriteFailure(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t)
+	fake.writeErr = io.ErrShortWrite
+	c.conn = fake
+
+	if err := c.sendRunPullAll("RETURN 1", nil); err == nil {
+		t.Fatal("Expected error writing RUN and PULL_ALL")
+	}
+	if !c.broken {
+		t.Fatal("Expected connection to be broken after a failed write")
+	}
+}
+
+func TestBoltConn_EncodeFailureBeforeWrite(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t)
+	c.conn = fake
+
+	// Nothing is written when the whole message fits in a chunk,
+	// so the connection can still be used
+	if err := c.sendRun("RETURN {ch}", map[string]interface{}{"ch": make(chan int)}); err == nil {
+		t.Fatal("Expected error encoding unsupported parameter")
+	}
+	if len(fake.writes) != 0 || c.broken {
+		t.Fatal("Expected connection to be usable after failing to encode before writing")
+	}
+}
+
+func TestBoltConn_UnwindBatched(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	created := messages.NewSuccessMessage(map[string]interface{}{
+		"stats": map[string]interface{}{"nodes-created": int64(250), "properties-set": int64(250)},
+	})
+
+	c := createBoltConn("")
+	fake := newFakeConn(t, success, created, success, created, success, created, success, created)
+	c.conn = fake
+
+	items := make([]interface{}, 1000)
+	for i := range items {
+		items[i] = i
+	}
+
+	counters, err := c.UnwindBatched("UNWIND {items} AS i CREATE (n {i: i})", "items", items, 250)
+	if err != nil {
+		t.Fatalf("An error occurred running batches: %s", err)
+	}
+	if counters.NodesCreated != 1000 || counters.PropertiesSet != 1000 {
+		t.Fatalf("Unexpected summed counters: %#v", counters)
+	}
+	if runs := bytes.Count(bytes.Join(fake.writes, nil), []byte("UNWIND")); runs != 4 {
+		t.Fatalf("Expected 4 batches to be run. Got: %d", runs)
+	}
+}
+
+func TestBoltConn_InTransaction(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	failure := messages.NewFailureMessage(map[string]interface{}{"code": "Neo.ClientError.Statement.SyntaxError"})
+	c := createBoltConn("")
+	c.conn = newFakeConn(t,
+		success, success, // BEGIN
+		success, success, // COMMIT
+		success, success, // BEGIN
+		failure, messages.NewIgnoredMessage(), success, // failed query
+		success, success, // ROLLBACK
+		success, success, // BEGIN
+		failure, messages.NewIgnoredMessage(), success, // failed COMMIT
+	)
+
+	if c.InTransaction() {
+		t.Fatal("Expected no transaction before begin")
+	}
+
+	tx, err := c.Begin()
+	if err != nil {
+		t.Fatalf("An error occurred beginning transaction: %s", err)
+	}
+	if !c.InTransaction() {
+		t.Fatal("Expected transaction after begin")
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("An error occurred committing transaction: %s", err)
+	}
+	if c.InTransaction() {
+		t.Fatal("Expected no transaction after commit")
+	}
+
+	tx, err = c.Begin()
+	if err != nil {
+		t.Fatalf("An error occurred beginning transaction: %s", err)
+	}
+	if _, err := c.ExecNeo("RETURN", nil); err == nil {
+		t.Fatal("Expected error executing failing query")
+	}
+	if !c.InTransaction() {
+		t.Fatal("Expected transaction to stay open after a failed query until it is rolled back")
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("An error occurred rolling back transaction: %s", err)
+	}
+	if c.InTransaction() {
+		t.Fatal("Expected no transaction after rollback")
+	}
+
+	tx, err = c.Begin()
+	if err != nil {
+		t.Fatalf("An error occurred beginning transaction: %s", err)
+	}
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Expected error from failing commit")
+	}
+	if c.InTransaction() {
+		t.Fatal("Expected no transaction after a failed commit")
+	}
+}
+
+func TestBoltConn_QueryNeoLimit(t *testing.T) {
+	run := messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"n"}})
+	record := func(n int64) messages.RecordMessage { return messages.NewRecordMessage([]interface{}{n}) }
+	pulled := messages.NewSuccessMessage(map[string]interface{}{"type": "r"})
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+
+	tests := []struct {
+		limit       RowLimit
+		expected    [][]interface{}
+		expectedErr bool
+	}{
+		{RowLimit{MaxRows: 2}, [][]interface{}{{int64(1)}, {int64(2)}}, true},
+		{RowLimit{MaxRows: 2, Truncate: true}, [][]interface{}{{int64(1)}, {int64(2)}}, false},
+		{RowLimit{MaxRows: 4}, [][]interface{}{{int64(1)}, {int64(2)}, {int64(3)}, {int64(4)}}, false},
+		{RowLimit{MaxRows: 10}, [][]interface{}{{int64(1)}, {int64(2)}, {int64(3)}, {int64(4)}}, false},
+	}
+
+	for _, test := range tests {
+		c := createBoltConn("")
+		fake := newFakeConn(t, run, record(1), record(2), record(3), record(4), pulled, success, success)
+		c.conn = fake
+
+		rows, err := c.QueryNeoLimit("UNWIND range(1, 4) AS n RETURN n", nil, test.limit)
+		if err != nil {
+			t.Fatalf("An error occurred querying with limit %#v: %s", test.limit, err)
+		}
+		data, _, err := rows.All()
+		if test.expectedErr {
+			if !errors.IsRowLimitExceeded(err) {
+				t.Fatalf("Expected row limit error with limit %#v. Got: %v", test.limit, err)
+			}
+		} else if err != nil {
+			t.Fatalf("An error occurred reading rows with limit %#v: %s", test.limit, err)
+		}
+		if !reflect.DeepEqual(data, test.expected) {
+			t.Fatalf("Unexpected rows with limit %#v. Expected %#v. Got: %#v", test.limit, test.expected, data)
+		}
+		if err := rows.Close(); err != nil {
+			t.Fatalf("An error occurred closing rows: %s", err)
+		}
+
+		// The rest of the rows are discarded, so the conn can be reused
+		if _, err := c.ExecNeo("CREATE (n)", nil); err != nil {
+			t.Fatalf("An error occurred reusing the conn after limit %#v: %s", test.limit, err)
+		}
+		if fake.reads.Len() != 0 {
+			t.Fatalf("Expected all responses to be read with limit %#v", test.limit)
+		}
+	}
+
+	if _, err := createBoltConn("").QueryNeoLimit("RETURN 1", nil, RowLimit{}); err == nil {
+		t.Fatal("Expected error querying without a max number of rows")
+	}
+}
+
+func TestBoltConn_RunBatch(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"a"}}),
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"b"}}),
+		messages.NewRecordMessage([]interface{}{int64(2)}),
+		messages.NewRecordMessage([]interface{}{int64(3)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"c"}}),
+		messages.NewRecordMessage([]interface{}{"four"}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+	c.conn = fake
+
+	results, err := c.RunBatch([]Statement{
+		{Query: "RETURN 1 AS a"},
+		{Query: "UNWIND [2, 3] AS b RETURN b"},
+		{Query: "RETURN {c} AS c", Params: map[string]interface{}{"c": "four"}},
+	})
+	if err != nil {
+		t.Fatalf("An error occurred running batch: %s", err)
+	}
+	if fake.reads.Len() != 0 {
+		t.Fatal("Expected all of the results to be read from the connection")
+	}
+
+	expected := []struct {
+		columns []string
+		data    [][]interface{}
+	}{
+		{[]string{"a"}, [][]interface{}{{int64(1)}}},
+		{[]string{"b"}, [][]interface{}{{int64(2)}, {int64(3)}}},
+		{[]string{"c"}, [][]interface{}{{"four"}}},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results. Got: %d", len(expected), len(results))
+	}
+
+	// Read them out of order, since they don't hold the connection
+	for i := len(results) - 1; i >= 0; i-- {
+		if !reflect.DeepEqual(results[i].Columns(), expected[i].columns) {
+			t.Fatalf("Unexpected columns for result %d: %#v", i, results[i].Columns())
+		}
+		data, _, err := results[i].All()
+		if err != nil {
+			t.Fatalf("An error occurred reading result %d: %s", i, err)
+		}
+		if !reflect.DeepEqual(data, expected[i].data) {
+			t.Fatalf("Unexpected data for result %d. Expected %#v. Got: %#v", i, expected[i].data, data)
+		}
+		if err := results[i].Close(); err != nil {
+			t.Fatalf("An error occurred closing result %d: %s", i, err)
+		}
+	}
+}
+
+func TestBoltConn_RunBatchFailure(t *testing.T) {
+	c := createBoltConn("")
+	fake := newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"a"}}),
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+		messages.NewFailureMessage(map[string]interface{}{"code": "Neo.ClientError.Statement.SyntaxError"}),
+		messages.NewIgnoredMessage(),
+		messages.NewIgnoredMessage(),
+		messages.NewIgnoredMessage(),
+		messages.NewSuccessMessage(map[string]interface{}{}),
+	)
+	c.conn = fake
+
+	results, err := c.RunBatch([]Statement{
+		{Query: "RETURN 1 AS a"},
+		{Query: "RETURN"},
+		{Query: "RETURN 3 AS c"},
+	})
+	if err == nil {
+		t.Fatal("Expected error running batch with a failing statement")
+	}
+	if !strings.Contains(err.Error(), "statement 1 of batch") {
+		t.Fatalf("Expected error to name the failing statement. Got: %s", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected the result before the failure to be returned. Got: %d results", len(results))
+	}
+	if fake.reads.Len() != 0 {
+		t.Fatal("Expected the ignored responses to be read from the connection")
+	}
+
+	if c.statement != nil {
+		t.Fatal("Expected no statement to be left open")
+	}
+}
+
+func TestBoltConn_ExecuteRead(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	c := createBoltConn("")
+	fake := newFakeConn(t,
+		success, success,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"n"}}),
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		success,
+		success, success,
+	)
+	c.conn = fake
+
+	result, err := c.ExecuteRead(context.Background(), func(tx TxRunner) (interface{}, error) {
+		data, _, _, err := tx.QueryNeoAll("RETURN 1", nil)
+		if err != nil {
+			return nil, err
+		}
+		return data[0][0], nil
+	})
+	if err != nil {
+		t.Fatalf("An error occurred executing read: %s", err)
+	}
+	if result != int64(1) {
+		t.Fatalf("Unexpected read result: %#v", result)
+	}
+
+	written := bytes.Join(fake.writes, nil)
+	if !bytes.Contains(written, []byte("BEGIN")) || !bytes.Contains(written, []byte("COMMIT")) {
+		t.Fatal("Expected read to run in a committed transaction")
+	}
+}
+
+func TestBoltConn_ExecuteWrite(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	c := createBoltConn("")
+	fake := newFakeConn(t, success, success, success, success, success, success)
+	c.conn = fake
+
+	_, err := c.ExecuteWrite(context.Background(), func(tx TxRunner) (interface{}, error) {
+		return tx.ExecNeo("CREATE (n)", nil)
+	})
+	if err != nil {
+		t.Fatalf("An error occurred executing write: %s", err)
+	}
+
+	written := bytes.Join(fake.writes, nil)
+	if !bytes.Contains(written, []byte("COMMIT")) || bytes.Contains(written, []byte("ROLLBACK")) {
+		t.Fatal("Expected write to be committed")
+	}
+}
+
+func TestBoltConn_ExecuteWriteRollback(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	c := createBoltConn("")
+	fake := newFakeConn(t, success, success, success, success, success, success)
+	c.conn = fake
+
+	workErr := stdErrors.New("work failed")
+	_, err := c.ExecuteWrite(context.Background(), func(tx TxRunner) (interface{}, error) {
+		if _, err := tx.ExecNeo("CREATE (n)", nil); err != nil {
+			return nil, err
+		}
+		return nil, workErr
+	})
+	if err != workErr {
+		t.Fatalf("Expected the work's error. Got: %v", err)
+	}
+
+	written := bytes.Join(fake.writes, nil)
+	if !bytes.Contains(written, []byte("ROLLBACK")) || bytes.Contains(written, []byte("COMMIT")) {
+		t.Fatal("Expected write to be rolled back")
+	}
+}
+
+func TestBoltConn_ExecuteWriteTransientRetry(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	deadlock := messages.NewFailureMessage(map[string]interface{}{
+		"code":    "Neo.TransientError.Transaction.DeadlockDetected",
+		"message": "deadlock",
+	})
+
+	c := createBoltConn("")
+	c.txRetryBackoff = 0
+	c.conn = newFakeConn(t,
+		// First attempt deadlocks, the PULL_ALL is ignored, and it's rolled back
+		success, success,
+		deadlock, messages.NewIgnoredMessage(), success,
+		success, success,
+		// Second attempt commits
+		success, success,
+		success, success,
+		success, success,
+	)
+
+	attempts := 0
+	_, err := c.ExecuteWrite(context.Background(), func(tx TxRunner) (interface{}, error) {
+		attempts++
+		return tx.ExecNeo("CREATE (n)", nil)
+	})
+	if err != nil {
+		t.Fatalf("An error occurred executing write: %s", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("Expected 2 attempts. Got: %d", attempts)
+	}
+}
+
+func TestBoltConn_RetryPredicate(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	busy := messages.NewFailureMessage(map[string]interface{}{
+		"code":    "Neo.ClientError.Procedure.ProcedureCallFailed",
+		"message": "busy",
+	})
+	script := func() []interface{} {
+		return []interface{}{
+			// First attempt fails in the procedure, and it's rolled back
+			success, success,
+			busy, messages.NewIgnoredMessage(), success,
+			success, success,
+			// Second attempt commits
+			success, success,
+			success, success,
+			success, success,
+		}
+	}
+	work := func(attempts *int) TxWork {
+		return func(tx TxRunner) (interface{}, error) {
+			*attempts++
+			return tx.ExecNeo("CALL custom.busy()", nil)
+		}
+	}
+
+	c := createBoltConn("")
+	c.txRetryBackoff = 0
+	c.conn = newFakeConn(t, script()...)
+	attempts := 0
+	if _, err := c.ExecuteWrite(context.Background(), work(&attempts)); err == nil {
+		t.Fatal("Expected error executing write without a retry predicate")
+	}
+	if attempts != 1 {
+		t.Fatalf("Expected non-transient error not to be retried. Got %d attempts", attempts)
+	}
+
+	c = createBoltConn("")
+	c.txRetryBackoff = 0
+	c.conn = newFakeConn(t, script()...)
+	c.SetRetryPredicate(func(err error) bool {
+		serverErr, ok := errors.Cause(err).(*errors.ServerError)
+		return ok && serverErr.Message == "busy"
+	})
+	attempts = 0
+	if _, err := c.ExecuteWrite(context.Background(), work(&attempts)); err != nil {
+		t.Fatalf("An error occurred executing write: %s", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("Expected the predicate to retry the error. Got %d attempts", attempts)
+	}
+}
+
+func TestBoltConn_QueryScalar(t *testing.T) {
+	scalar := func(val interface{}) *boltConn {
+		c := createBoltConn("")
+		c.conn = newFakeConn(t,
+			messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"x"}}),
+			messages.NewRecordMessage([]interface{}{val}),
+			messages.NewSuccessMessage(map[string]interface{}{}),
+		)
+		return c
+	}
+
+	var count int
+	if err := scalar(int64(42)).QueryScalar("MATCH (n) RETURN count(n)", nil, &count); err != nil {
+		t.Fatalf("An error occurred querying int scalar: %s", err)
+	}
+	if count != 42 {
+		t.Fatalf("Unexpected int scalar: %d", count)
+	}
+
+	var name string
+	c := scalar("foo")
+	if err := c.QueryScalar("RETURN 'foo'", nil, &name); err != nil {
+		t.Fatalf("An error occurred querying string scalar: %s", err)
+	}
+	if name != "foo" {
+		t.Fatalf("Unexpected string scalar: %s", name)
+	}
+	if c.statement != nil {
+		t.Fatal("Expected statement to be closed after scalar query")
+	}
+}
+
+func TestBoltConn_QueryScalarWrongShape(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	var x int64
+
+	c := createBoltConn("")
+	c.conn = newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"a", "b"}}),
+		messages.NewRecordMessage([]interface{}{int64(1), int64(2)}),
+		success,
+	)
+	if err := c.QueryScalar("RETURN 1 AS a, 2 AS b", nil, &x); err == nil {
+		t.Fatal("Expected error querying scalar with 2 columns")
+	}
+
+	c = createBoltConn("")
+	c.conn = newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"x"}}),
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewRecordMessage([]interface{}{int64(2)}),
+		success,
+	)
+	if err := c.QueryScalar("UNWIND [1, 2] AS x RETURN x", nil, &x); err == nil {
+		t.Fatal("Expected error querying scalar with 2 rows")
+	}
+
+	c = createBoltConn("")
+	c.conn = newFakeConn(t,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"x"}}),
+		success,
+	)
+	if err := c.QueryScalar("UNWIND [] AS x RETURN x", nil, &x); err == nil {
+		t.Fatal("Expected error querying scalar with no rows")
+	}
 }
 
 func TestBoltConn_Close(t *testing.T) {
@@ -53,6 +53,19 @@ TLS is supported by using query parameters on the connection string, like so:
 The supported query params are:
 
 * timeout - the number of seconds to set the connection timeout to. Defaults to 60 seconds.
+* handshake_timeout - the number of seconds to allow for the handshake and INIT when connecting. Defaults to no separate limit.
+* connect_attempts - the number of times to try dialing, handshaking and initializing before failing. Auth failures aren't retried. Defaults to 1.
+* connect_backoff_ms - the number of milliseconds to wait before retrying a failed connect. Doubles after each attempt. Defaults to 0.
+* min_protocol_version - the lowest Bolt protocol version to accept from the handshake. Connecting fails straight after the handshake if the server negotiates an older one. The driver only proposes version 1, so a minimum above 1 always fails. Defaults to no minimum.
+* tx_attempts - the number of times ExecuteRead and ExecuteWrite try a transaction that fails with a transient error. Defaults to 3.
+* tx_retry_backoff_ms - the number of milliseconds to wait before retrying a transaction. Doubles after each attempt. Defaults to 100.
+* trace_dump_limit - the max number of bytes of each read and write to hex dump when logging at trace level. 0 for no limit. Defaults to 4096.
+* dns_cache_ttl - the number of seconds to cache the addresses the host resolves to, rotating between them for each connection. Defaults to 0, resolving on every dial.
+* local_addr - the local IP address, optionally with a port, to dial from. Useful on hosts with multiple interfaces. Defaults to one chosen by the OS.
+* encode_structs - Set to 'true' or '1' to encode struct parameters as maps of their exported fields, named by their bolt or json tags. See encoding.Encoder's EncodeStructs.
+* error_on_unknown_structure - Set to 'true' or '1' to error when the server sends a structure the driver doesn't know, rather than decoding it as a structures.GenericStructure. See encoding.Decoder's ErrorOnUnknownStructure.
+* statement_prefix - text to add, followed by a space, to the start of every query run on the connection, such as 'CYPHER runtime=slotted'. Not added to the BEGIN, COMMIT and ROLLBACK statements of transactions. Bolt v1 servers predate multiple databases, so this can't be used to select a database with USE. Defaults to no prefix.
+* conn_name - a name to prefix the connection's id with in logs, to help correlate them
 * tls - Set to 'true' or '1' if you want to use TLS encryption
 * tls_no_verify - Set to 'true' or '1' if you want to accept any server certificate (for testing, not secure)
 * tls_ca_cert_file - path to a custom ca cert for a self-signed TLS cert
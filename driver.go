@@ -1,8 +1,14 @@
 package golangNeo4jBoltDriver
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
+	"sync"
+	"time"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/log"
 )
 
 var (
@@ -42,7 +48,8 @@ type Driver interface {
 }
 
 type boltDriver struct {
-	recorder *recorder
+	recorder   *recorder
+	authTokens *authTokenCache
 }
 
 // NewDriver creates a new Driver object
@@ -50,6 +57,47 @@ func NewDriver() Driver {
 	return &boltDriver{}
 }
 
+// NewDriverWithAuthTokenProvider creates a new Driver object that authenticates
+// connections with the auth tokens given by the provider, instead of the user
+// and password in the connection string.  Useful for short-lived, rotating tokens.
+func NewDriverWithAuthTokenProvider(provider AuthTokenProvider) Driver {
+	return &boltDriver{authTokens: &authTokenCache{provider: provider}}
+}
+
+// AuthTokenProvider gets an auth token to send to the server when initializing
+// a connection, such as: {"scheme": "bearer", "credentials": token}.
+// The token is cached until the server rejects it, so the provider is only
+// called on the first connect and after the token expires.  The context is
+// done when the handshake timeout of the connection, if any, expires.
+type AuthTokenProvider func(ctx context.Context) (map[string]interface{}, error)
+
+// authTokenCache caches the token from a provider until the server rejects it
+type authTokenCache struct {
+	provider AuthTokenProvider
+	mu       sync.Mutex
+	token    map[string]interface{}
+}
+
+func (a *authTokenCache) get(ctx context.Context) (map[string]interface{}, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == nil {
+		token, err := a.provider(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "An error occurred getting auth token from provider")
+		}
+		a.token = token
+	}
+	return a.token, nil
+}
+
+func (a *authTokenCache) invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.token = nil
+}
+
 // Open opens a new Bolt connection to the Neo4J database
 func (d *boltDriver) Open(connStr string) (driver.Conn, error) {
 	return newBoltConn(connStr, d) // Never use pooling when using SQL driver
@@ -69,22 +117,64 @@ func (d *boltDriver) OpenNeo(connStr string) (Conn, error) {
 // THREAD SAFE.
 type DriverPool interface {
 	// OpenPool opens a Neo-specific connection.
+	// The conn uses the same underlying connection until it's closed,
+	// so reads on it always see the writes made on it before them.
 	OpenPool() (Conn, error)
+	// Stats gets statistics about acquiring connections from the pool
+	Stats() PoolStats
 	reclaim(*boltConn)
 }
 
+// PoolStats holds statistics about acquiring connections from a DriverPool
+type PoolStats struct {
+	// Acquisitions is the number of connections acquired from the pool
+	Acquisitions int64
+	// TotalWait is the total time spent waiting for connections to become available
+	TotalWait time.Duration
+	// MaxWait is the longest time spent waiting for a connection to become available
+	MaxWait time.Duration
+}
+
 type boltDriverPool struct {
-	connStr  string
-	maxConns int
-	pool     chan *boltConn
+	connStr    string
+	maxConns   int
+	pool       chan *boltConn
+	limiter    *ConnLimiter
+	authTokens *authTokenCache
+	statsMu    sync.Mutex
+	stats      PoolStats
 }
 
 // NewDriverPool creates a new Driver object with connection pooling
 func NewDriverPool(connStr string, max int) (DriverPool, error) {
+	return NewLimitedDriverPool(connStr, max, nil)
+}
+
+// NewLimitedDriverPool creates a new Driver object with connection pooling,
+// where opening connections is limited by the given limiter.  The same limiter
+// can be shared by multiple pools to cap their combined number of open connections.
+func NewLimitedDriverPool(connStr string, max int, limiter *ConnLimiter) (DriverPool, error) {
+	return newDriverPool(connStr, max, limiter, nil)
+}
+
+// NewDriverPoolWithAuthTokenProvider creates a new Driver object with
+// connection pooling, that authenticates connections with the auth tokens
+// given by the provider, as NewDriverWithAuthTokenProvider does.  The pool's
+// connections share the cached token.  limiter may be nil for no limit.
+func NewDriverPoolWithAuthTokenProvider(connStr string, max int, limiter *ConnLimiter, provider AuthTokenProvider) (DriverPool, error) {
+	return newDriverPool(connStr, max, limiter, &authTokenCache{provider: provider})
+}
+
+func newDriverPool(connStr string, max int, limiter *ConnLimiter, authTokens *authTokenCache) (DriverPool, error) {
 	d := &boltDriverPool{
-		connStr:  connStr,
-		maxConns: max,
-		pool:     make(chan *boltConn, max),
+		connStr:    connStr,
+		maxConns:   max,
+		pool:       make(chan *boltConn, max),
+		limiter:    limiter,
+		authTokens: authTokens,
+	}
+	if limiter != nil {
+		limiter.addPool(d)
 	}
 
 	for i := 0; i < max; i++ {
@@ -101,22 +191,182 @@ func NewDriverPool(connStr string, max int) (DriverPool, error) {
 
 // OpenNeo opens a new Bolt connection to the Neo4J database.
 func (d *boltDriverPool) OpenPool() (Conn, error) {
+	start := time.Now()
 	conn := <-d.pool
+	d.recordAcquisition(time.Since(start))
+
 	if conn.conn == nil {
+		if d.limiter != nil {
+			if err := d.limiter.acquire(); err != nil {
+				d.pool <- conn
+				return nil, err
+			}
+		}
+
 		if err := conn.initialize(); err != nil {
+			if d.limiter != nil {
+				d.limiter.release()
+			}
+			// Put the conn back so it can be initialized on the next open
+			conn.conn = nil
+			d.pool <- conn
 			return nil, err
 		}
 	}
 	return conn, nil
 }
 
+// Stats gets statistics about acquiring connections from the pool
+func (d *boltDriverPool) Stats() PoolStats {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+	return d.stats
+}
+
+func (d *boltDriverPool) recordAcquisition(wait time.Duration) {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+
+	d.stats.Acquisitions++
+	d.stats.TotalWait += wait
+	if wait > d.stats.MaxWait {
+		d.stats.MaxWait = wait
+	}
+}
+
 func (d *boltDriverPool) reclaim(conn *boltConn) {
+	if conn.conn == nil && d.limiter != nil {
+		// The conn was dropped, so it no longer counts towards the limit
+		d.limiter.release()
+	}
+
 	// sneakily swap out connection so a reference to
 	// it isn't held on to
 	newConn := &boltConn{}
 	*newConn = *conn
 	d.pool <- newConn
 	conn = nil
+
+	if newConn.conn != nil && d.limiter != nil {
+		d.limiter.notifyIdle()
+	}
+}
+
+// closeIdle closes one of the idle, open conns in the pool, if there are
+// any, so it no longer counts towards the limit.  It will be reconnected
+// the next time it's opened.
+func (d *boltDriverPool) closeIdle() bool {
+	for i := 0; i < d.maxConns; i++ {
+		var conn *boltConn
+		select {
+		case conn = <-d.pool:
+		default:
+			return false
+		}
+
+		closed := conn.conn != nil
+		if closed {
+			if err := conn.conn.Close(); err != nil {
+				log.Errorf("[%s] An error occurred closing idle connection: %s", conn.ID(), err)
+			}
+			conn.conn = nil
+			d.limiter.release()
+		}
+		d.pool <- conn
+
+		if closed {
+			return true
+		}
+	}
+	return false
+}
+
+// ConnLimiter limits the number of open connections across all of the
+// DriverPools sharing it.  See NewLimitedDriverPool.
+type ConnLimiter struct {
+	slots   chan struct{}
+	idle    chan struct{}
+	timeout time.Duration
+	poolsMu sync.Mutex
+	pools   []*boltDriverPool
+}
+
+// NewConnLimiter creates a new limiter allowing up to max open connections.
+// When the limit is hit, an idle connection in one of the pools is closed to
+// make room.  If there are none, opening a new connection waits up to timeout
+// for one to become idle or close before failing.  A timeout of 0 waits
+// indefinitely.
+func NewConnLimiter(max int, timeout time.Duration) *ConnLimiter {
+	return &ConnLimiter{
+		slots:   make(chan struct{}, max),
+		idle:    make(chan struct{}, 1),
+		timeout: timeout,
+	}
+}
+
+// Open gets the number of connections currently open under the limiter
+func (l *ConnLimiter) Open() int {
+	return len(l.slots)
+}
+
+func (l *ConnLimiter) addPool(d *boltDriverPool) {
+	l.poolsMu.Lock()
+	defer l.poolsMu.Unlock()
+	l.pools = append(l.pools, d)
+}
+
+func (l *ConnLimiter) acquire() error {
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		timeout = time.After(l.timeout)
+	}
+
+	for {
+		select {
+		case l.slots <- struct{}{}:
+			return nil
+		default:
+		}
+
+		if l.closeIdle() {
+			continue
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+			return nil
+		case <-l.idle:
+			// A conn went back to its pool, so it can be closed to make room
+		case <-timeout:
+			return errors.New("Timed out waiting for a connection under the limit of %d open connections", cap(l.slots))
+		}
+	}
+}
+
+// closeIdle closes an idle conn in one of the pools sharing the limiter
+func (l *ConnLimiter) closeIdle() bool {
+	l.poolsMu.Lock()
+	pools := append([]*boltDriverPool(nil), l.pools...)
+	l.poolsMu.Unlock()
+
+	for _, pool := range pools {
+		if pool.closeIdle() {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyIdle wakes a waiting acquire when a conn goes back to its pool
+func (l *ConnLimiter) notifyIdle() {
+	select {
+	case l.idle <- struct{}{}:
+	default:
+	}
+}
+
+func (l *ConnLimiter) release() {
+	<-l.slots
 }
 
 func init() {
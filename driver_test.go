@@ -1,13 +1,23 @@
 package golangNeo4jBoltDriver
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
 	"os"
+	"strconv"
 	"testing"
 
 	"time"
 
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/encoding"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/log"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/messages"
 	"sync"
+	"sync/atomic"
 )
 
 var (
@@ -53,6 +63,359 @@ func clearNeo() {
 	}
 }
 
+// newFakeServer starts a fake bolt server that accepts the handshake and
+// INIT of every connection, returning the connection string for it
+func newFakeServer(t *testing.T) (string, func()) {
+	connStr, _, closeServer := newFlakyFakeServer(t, 0, messages.NewSuccessMessage(map[string]interface{}{}))
+	return connStr, closeServer
+}
+
+// newFlakyFakeServer starts a fake server that drops the first rejects
+// connections, then responds to INIT with initResp.  Also returns the number
+// of connections accepted so far.
+func newFlakyFakeServer(t *testing.T, rejects int, initResp interface{}) (string, *int32, func()) {
+	return newVersionedFakeServer(t, rejects, []byte{0x00, 0x00, 0x00, 0x01}, initResp)
+}
+
+// newVersionedFakeServer is like newFlakyFakeServer, but responds to the
+// handshake with the given version
+func newVersionedFakeServer(t *testing.T, rejects int, version []byte, initResp interface{}) (string, *int32, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("An error occurred starting fake server: %s", err)
+	}
+
+	resp, err := encoding.Marshal(initResp)
+	if err != nil {
+		t.Fatalf("An error occurred encoding fake server init response: %s", err)
+	}
+
+	var accepted int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			if int(atomic.AddInt32(&accepted, 1)) <= rejects {
+				conn.Close()
+				continue
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				if _, err := io.ReadFull(conn, make([]byte, len(handShake))); err != nil {
+					return
+				}
+				if _, err := conn.Write(version); err != nil {
+					return
+				}
+				// INIT isn't a decodable response type, but reading it
+				// out of the stream is all that's needed here
+				encoding.NewDecoder(conn).Decode()
+				if _, err := conn.Write(resp); err != nil {
+					return
+				}
+				io.Copy(ioutil.Discard, conn)
+			}(conn)
+		}
+	}()
+
+	return "bolt://" + listener.Addr().String(), &accepted, func() { listener.Close() }
+}
+
+func TestBoltDriver_ConnectRetry(t *testing.T) {
+	connStr, accepted, closeServer := newFlakyFakeServer(t, 2, messages.NewSuccessMessage(map[string]interface{}{}))
+	defer closeServer()
+
+	conn, err := NewDriver().OpenNeo(connStr + "?connect_attempts=3&connect_backoff_ms=10")
+	if err != nil {
+		t.Fatalf("An error occurred opening conn after transient failures: %s", err)
+	}
+	defer conn.Close()
+
+	if n := atomic.LoadInt32(accepted); n != 3 {
+		t.Fatalf("Expected 3 connection attempts. Got: %d", n)
+	}
+}
+
+func TestBoltDriver_LocalAddr(t *testing.T) {
+	connStr, closeServer := newFakeServer(t)
+	defer closeServer()
+
+	// Find a free local port to bind to
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("An error occurred finding a free port: %s", err)
+	}
+	localAddr := listener.Addr().String()
+	listener.Close()
+
+	conn, err := NewDriver().OpenNeo(connStr + "?local_addr=" + localAddr)
+	if err != nil {
+		t.Fatalf("An error occurred opening conn with local address: %s", err)
+	}
+	defer conn.Close()
+
+	if addr := conn.(*boltConn).conn.LocalAddr().String(); addr != localAddr {
+		t.Fatalf("Expected connection from %s. Got: %s", localAddr, addr)
+	}
+}
+
+func TestBoltDriver_ConnectRetryExhausted(t *testing.T) {
+	connStr, accepted, closeServer := newFlakyFakeServer(t, 2, messages.NewSuccessMessage(map[string]interface{}{}))
+	defer closeServer()
+
+	if _, err := NewDriver().OpenNeo(connStr + "?connect_attempts=2"); err == nil {
+		t.Fatal("Expected error when connect attempts are exhausted")
+	}
+
+	if n := atomic.LoadInt32(accepted); n != 2 {
+		t.Fatalf("Expected 2 connection attempts. Got: %d", n)
+	}
+}
+
+func TestBoltDriver_ConnectRetryAuthFailure(t *testing.T) {
+	failure := messages.NewFailureMessage(map[string]interface{}{
+		"code":    errors.UnauthorizedCode,
+		"message": "The client is unauthorized due to authentication failure.",
+	})
+	connStr, accepted, closeServer := newFlakyFakeServer(t, 0, failure)
+	defer closeServer()
+
+	_, err := NewDriver().OpenNeo(connStr + "?connect_attempts=3")
+	if _, ok := errors.Cause(err).(*errors.AuthError); !ok {
+		t.Fatalf("Expected auth error. Got: %#v", err)
+	}
+
+	if n := atomic.LoadInt32(accepted); n != 1 {
+		t.Fatalf("Expected auth failure not to be retried. Got %d connection attempts", n)
+	}
+}
+
+func TestBoltDriver_ConnectRetryMinVersion(t *testing.T) {
+	connStr, accepted, closeServer := newFlakyFakeServer(t, 0, messages.NewSuccessMessage(map[string]interface{}{}))
+	defer closeServer()
+
+	_, err := NewDriver().OpenNeo(connStr + "?connect_attempts=3&min_protocol_version=2")
+	if !errors.IsProtocolVersion(err) {
+		t.Fatalf("Expected protocol version error. Got: %#v", err)
+	}
+
+	if n := atomic.LoadInt32(accepted); n != 1 {
+		t.Fatalf("Expected protocol version rejection not to be retried. Got %d connection attempts", n)
+	}
+}
+
+func TestBoltDriver_ConnectRetryNoSupportedVersion(t *testing.T) {
+	connStr, accepted, closeServer := newVersionedFakeServer(t, 0, noVersionSupported, messages.NewSuccessMessage(map[string]interface{}{}))
+	defer closeServer()
+
+	_, err := NewDriver().OpenNeo(connStr + "?connect_attempts=3")
+	if !errors.IsProtocolVersion(err) {
+		t.Fatalf("Expected protocol version error. Got: %#v", err)
+	}
+
+	if n := atomic.LoadInt32(accepted); n != 1 {
+		t.Fatalf("Expected no supported version not to be retried. Got %d connection attempts", n)
+	}
+}
+
+func TestBoltDriverPool_ConnLimiter(t *testing.T) {
+	connStr, closeServer := newFakeServer(t)
+	defer closeServer()
+
+	limiter := NewConnLimiter(2, 50*time.Millisecond)
+	poolA, err := NewLimitedDriverPool(connStr, 2, limiter)
+	if err != nil {
+		t.Fatalf("An error occurred opening driver pool: %s", err)
+	}
+	poolB, err := NewLimitedDriverPool(connStr, 2, limiter)
+	if err != nil {
+		t.Fatalf("An error occurred opening driver pool: %s", err)
+	}
+
+	connA, err := poolA.OpenPool()
+	if err != nil {
+		t.Fatalf("An error occurred opening conn from first pool: %s", err)
+	}
+	defer connA.Close()
+
+	connB, err := poolB.OpenPool()
+	if err != nil {
+		t.Fatalf("An error occurred opening conn from second pool: %s", err)
+	}
+	defer connB.Close()
+
+	if limiter.Open() != 2 {
+		t.Fatalf("Expected 2 open connections. Got: %d", limiter.Open())
+	}
+
+	if _, err := poolA.OpenPool(); err == nil {
+		t.Fatal("Expected error opening conn over the global limit from first pool")
+	}
+	if _, err := poolB.OpenPool(); err == nil {
+		t.Fatal("Expected error opening conn over the global limit from second pool")
+	}
+
+	if limiter.Open() != 2 {
+		t.Fatalf("Expected open connections to stay at the limit of 2. Got: %d", limiter.Open())
+	}
+}
+
+func TestBoltDriverPool_ConnLimiterIdle(t *testing.T) {
+	connStr, closeServer := newFakeServer(t)
+	defer closeServer()
+
+	// No timeout, so this would block forever if the idle conn kept its slot
+	limiter := NewConnLimiter(1, 0)
+	poolA, err := NewLimitedDriverPool(connStr, 1, limiter)
+	if err != nil {
+		t.Fatalf("An error occurred opening driver pool: %s", err)
+	}
+	poolB, err := NewLimitedDriverPool(connStr, 1, limiter)
+	if err != nil {
+		t.Fatalf("An error occurred opening driver pool: %s", err)
+	}
+
+	connA, err := poolA.OpenPool()
+	if err != nil {
+		t.Fatalf("An error occurred opening conn from first pool: %s", err)
+	}
+	if err := connA.Close(); err != nil {
+		t.Fatalf("An error occurred closing conn from first pool: %s", err)
+	}
+
+	opened := make(chan error, 1)
+	go func() {
+		connB, err := poolB.OpenPool()
+		if err == nil {
+			err = connB.Close()
+		}
+		opened <- err
+	}()
+
+	select {
+	case err := <-opened:
+		if err != nil {
+			t.Fatalf("An error occurred opening conn from second pool: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the idle conn in the first pool to make room for the second pool")
+	}
+
+	if limiter.Open() != 1 {
+		t.Fatalf("Expected 1 open connection. Got: %d", limiter.Open())
+	}
+
+	// The first pool's conn was closed while idle, and is reconnected
+	connA, err = poolA.OpenPool()
+	if err != nil {
+		t.Fatalf("An error occurred reopening conn from first pool: %s", err)
+	}
+	if err := connA.Close(); err != nil {
+		t.Fatalf("An error occurred closing conn from first pool: %s", err)
+	}
+}
+
+func TestBoltDriver_AuthTokenProvider(t *testing.T) {
+	var calls int
+	provider := func(ctx context.Context) (map[string]interface{}, error) {
+		calls++
+		return map[string]interface{}{"scheme": "bearer", "credentials": "token" + strconv.Itoa(calls)}, nil
+	}
+	d := NewDriverWithAuthTokenProvider(provider).(*boltDriver)
+
+	connect := func(resp interface{}) (*fakeConn, error) {
+		fake := newFakeConn(t)
+		fake.reads.Write([]byte{0x00, 0x00, 0x00, 0x01})
+		respBytes, err := encoding.Marshal(resp)
+		if err != nil {
+			t.Fatalf("An error occurred encoding INIT response: %s", err)
+		}
+		fake.reads.Write(respBytes)
+
+		c := createBoltConn("")
+		c.driver = d
+		c.authTokens = d.authTokens
+		c.conn = fake
+		return fake, c.negotiate()
+	}
+
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	fake, err := connect(success)
+	if err != nil {
+		t.Fatalf("An error occurred connecting: %s", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected provider to be called on connect. Got %d calls", calls)
+	}
+
+	if written := bytes.Join(fake.writes[1:], nil); !bytes.Contains(written, []byte("token1")) {
+		t.Fatalf("Expected INIT with provided token. Got: %x", written)
+	}
+
+	if _, err := connect(success); err != nil {
+		t.Fatalf("An error occurred connecting: %s", err)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected provided token to be cached. Got %d calls", calls)
+	}
+
+	expired := messages.NewFailureMessage(map[string]interface{}{"code": errors.TokenExpiredCode, "message": "expired"})
+	_, err = connect(expired)
+	authErr, ok := errors.Cause(err).(*errors.AuthError)
+	if !ok {
+		t.Fatalf("Expected auth error. Got: %#v", err)
+	}
+	if !authErr.IsTokenExpired() {
+		t.Fatalf("Expected token expired error. Got: %s", err)
+	}
+
+	if _, err := connect(success); err != nil {
+		t.Fatalf("An error occurred connecting: %s", err)
+	}
+	if calls != 2 {
+		t.Fatalf("Expected provider to be called again after token expired. Got %d calls", calls)
+	}
+}
+
+func TestBoltDriverPool_AuthTokenProvider(t *testing.T) {
+	connStr, closeServer := newFakeServer(t)
+	defer closeServer()
+
+	var calls int32
+	provider := func(ctx context.Context) (map[string]interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("Expected provider context to have the handshake deadline")
+		}
+		atomic.AddInt32(&calls, 1)
+		return map[string]interface{}{"scheme": "bearer", "credentials": "token"}, nil
+	}
+
+	pool, err := NewDriverPoolWithAuthTokenProvider(connStr+"?handshake_timeout=5", 2, nil, provider)
+	if err != nil {
+		t.Fatalf("An error occurred creating pool: %s", err)
+	}
+
+	var conns []Conn
+	for i := 0; i < 2; i++ {
+		conn, err := pool.OpenPool()
+		if err != nil {
+			t.Fatalf("An error occurred opening pooled conn: %s", err)
+		}
+		conns = append(conns, conn)
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("Expected pooled conns to share the provided token. Got %d calls", n)
+	}
+}
+
 func TestBoltDriverPool_OpenNeo(t *testing.T) {
 	if neo4jConnStr == "" {
 		t.Skip("Cannot run this test when in recording mode")
@@ -82,6 +445,40 @@ func TestBoltDriverPool_OpenNeo(t *testing.T) {
 	defer c.Close()
 }
 
+func TestBoltDriverPool_Stats(t *testing.T) {
+	d := &boltDriverPool{maxConns: 1, pool: make(chan *boltConn, 1)}
+	pooled, _ := newPooledBoltConn("", d)
+	pooled.conn = &fakeConn{}
+	d.pool <- pooled
+
+	conn, err := d.OpenPool()
+	if err != nil {
+		t.Fatalf("An error occurred opening conn from pool: %s", err)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		conn.Close()
+	}()
+
+	conn, err = d.OpenPool()
+	if err != nil {
+		t.Fatalf("An error occurred opening contended conn from pool: %s", err)
+	}
+	defer conn.Close()
+
+	stats := d.Stats()
+	if stats.Acquisitions != 2 {
+		t.Fatalf("Expected 2 acquisitions. Got: %d", stats.Acquisitions)
+	}
+	if stats.MaxWait < 10*time.Millisecond {
+		t.Fatalf("Expected max wait from contended acquisition. Got: %s", stats.MaxWait)
+	}
+	if stats.TotalWait < stats.MaxWait {
+		t.Fatalf("Expected total wait to include max wait. Got total %s max %s", stats.TotalWait, stats.MaxWait)
+	}
+}
+
 func TestBoltDriverPool_Concurrent(t *testing.T) {
 	if neo4jConnStr == "" {
 		t.Skip("Cannot run this test when in recording mode")
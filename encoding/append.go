@@ -0,0 +1,239 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures"
+)
+
+// AppendEncode appends the encoding of v to dst, and returns the extended
+// slice.  The output is the same as Marshal: the chunked message, with the
+// end message marker.  Unlike Encode, no io.Writer or intermediate buffers
+// are used, so encoding into a reused dst only allocates for what the values
+// themselves build, such as the fields from Structure.AllFields.
+//
+// On error, dst is returned as it was given.  Use Encoder.AppendEncode to
+// append with the options and chunk size of an Encoder.
+func AppendEncode(dst []byte, v interface{}) ([]byte, error) {
+	return Encoder{chunkSize: math.MaxUint16}.AppendEncode(dst, v)
+}
+
+// AppendEncode appends the encoding of v to dst, as Encode would write it
+// with the options and chunk size of the Encoder, and returns the extended
+// slice.  Nothing is written to the Encoder's writer.  An Encoder without a
+// chunk size, such as one that wasn't made with NewEncoder, uses chunks of
+// math.MaxUint16 bytes.
+//
+// On error, dst is returned as it was given.
+func (e Encoder) AppendEncode(dst []byte, v interface{}) ([]byte, error) {
+	start := len(dst)
+	dst, err := e.appendValue(dst, v)
+	if err != nil {
+		return dst[:start], err
+	}
+	return appendChunks(dst, start, e.appendChunkSize()), nil
+}
+
+// appendChunkSize gets the chunk size to append with
+func (e Encoder) appendChunkSize() int {
+	if e.chunkSize == 0 {
+		return math.MaxUint16
+	}
+	return int(e.chunkSize)
+}
+
+// appendChunks splits the data in dst after start into chunks of chunkSize,
+// in place, and appends the end message marker
+func appendChunks(dst []byte, start int, chunkSize int) []byte {
+	length := len(dst) - start
+	numChunks := (length + chunkSize - 1) / chunkSize
+
+	// Room for each chunk header, plus the end message,
+	// which is already zeroed
+	for i := 0; i < numChunks*2+len(EndMessage); i++ {
+		dst = append(dst, 0x00)
+	}
+
+	// Move the last chunk first, so data that's yet to be moved isn't overwritten
+	for i := numChunks - 1; i >= 0; i-- {
+		from := start + i*chunkSize
+		size := length - i*chunkSize
+		if size > chunkSize {
+			size = chunkSize
+		}
+
+		to := from + (i+1)*2
+		copy(dst[to:to+size], dst[from:from+size])
+		binary.BigEndian.PutUint16(dst[to-2:to], uint16(size))
+	}
+
+	return dst
+}
+
+// appendValue appends the encoding of a value, as encode writes it
+func (e Encoder) appendValue(dst []byte, iVal interface{}) ([]byte, error) {
+	iVal, err := e.normalize(iVal)
+	if err != nil {
+		return dst, err
+	}
+
+	switch val := iVal.(type) {
+	case nil:
+		return append(dst, NilMarker), nil
+	case bool:
+		if val {
+			return append(dst, TrueMarker), nil
+		}
+		return append(dst, FalseMarker), nil
+	case int:
+		return e.appendInt(dst, int64(val)), nil
+	case int8:
+		return e.appendInt(dst, int64(val)), nil
+	case int16:
+		return e.appendInt(dst, int64(val)), nil
+	case int32:
+		return e.appendInt(dst, int64(val)), nil
+	case int64:
+		return e.appendInt(dst, val), nil
+	case uint:
+		return e.appendInt(dst, int64(val)), nil
+	case uint8:
+		return e.appendInt(dst, int64(val)), nil
+	case uint16:
+		return e.appendInt(dst, int64(val)), nil
+	case uint32:
+		return e.appendInt(dst, int64(val)), nil
+	case uint64:
+		return e.appendInt(dst, int64(val)), nil
+	case float32:
+		return e.appendFloat(dst, float64(val))
+	case float64:
+		return e.appendFloat(dst, val)
+	case string:
+		return appendString(dst, val)
+	case []interface{}:
+		return e.appendSlice(dst, val)
+	case map[string]interface{}:
+		return e.appendMap(dst, val)
+	case structures.Structure:
+		return e.appendStructure(dst, val)
+	case RawValue:
+		return append(dst, val...), nil
+	default:
+		return dst, errors.New("Unrecognized type when encoding data for Bolt transport: %T %+v", val, val)
+	}
+}
+
+func (e Encoder) appendInt(dst []byte, val int64) []byte {
+	switch {
+	case e.FixedWidthInts:
+		dst = append(dst, Int64Marker)
+		return binary.BigEndian.AppendUint64(dst, uint64(val))
+	case val >= -16 && val <= math.MaxInt8:
+		return append(dst, byte(int8(val)))
+	case val >= math.MinInt8 && val <= math.MaxInt8:
+		return append(dst, Int8Marker, byte(int8(val)))
+	case val >= math.MinInt16 && val <= math.MaxInt16:
+		dst = append(dst, Int16Marker)
+		return binary.BigEndian.AppendUint16(dst, uint16(val))
+	case val >= math.MinInt32 && val <= math.MaxInt32:
+		dst = append(dst, Int32Marker)
+		return binary.BigEndian.AppendUint32(dst, uint32(val))
+	default:
+		dst = append(dst, Int64Marker)
+		return binary.BigEndian.AppendUint64(dst, uint64(val))
+	}
+}
+
+func (e Encoder) appendFloat(dst []byte, val float64) ([]byte, error) {
+	if e.RejectNonFinite && (math.IsNaN(val) || math.IsInf(val, 0)) {
+		return dst, errors.New("Non-finite float not allowed: %v", val)
+	}
+
+	dst = append(dst, FloatMarker)
+	return binary.BigEndian.AppendUint64(dst, math.Float64bits(val)), nil
+}
+
+// appendSize appends the marker for a string, slice, map or structure
+// of the given length.  Sizes that a marker can't hold return an error.
+func appendSize(dst []byte, length int, tiny byte, markers []byte) ([]byte, error) {
+	switch {
+	case length <= 15:
+		return append(dst, tiny+byte(length)), nil
+	case length <= math.MaxUint8:
+		return append(dst, markers[0], byte(length)), nil
+	case length <= math.MaxUint16:
+		dst = append(dst, markers[1])
+		return binary.BigEndian.AppendUint16(dst, uint16(length)), nil
+	case len(markers) > 2 && int64(length) <= math.MaxUint32:
+		dst = append(dst, markers[2])
+		return binary.BigEndian.AppendUint32(dst, uint32(length)), nil
+	default:
+		return dst, errors.New("Too long to write. Length: %d", length)
+	}
+}
+
+var (
+	stringMarkers = []byte{String8Marker, String16Marker, String32Marker}
+	sliceMarkers  = []byte{Slice8Marker, Slice16Marker, Slice32Marker}
+	mapMarkers    = []byte{Map8Marker, Map16Marker, Map32Marker}
+	structMarkers = []byte{Struct8Marker, Struct16Marker}
+)
+
+func appendString(dst []byte, val string) ([]byte, error) {
+	dst, err := appendSize(dst, len(val), TinyStringMarker, stringMarkers)
+	if err != nil {
+		return dst, errors.Wrap(err, "String too long to write")
+	}
+	return append(dst, val...), nil
+}
+
+func (e Encoder) appendSlice(dst []byte, val []interface{}) ([]byte, error) {
+	dst, err := appendSize(dst, len(val), TinySliceMarker, sliceMarkers)
+	if err != nil {
+		return dst, errors.Wrap(err, "Slice too long to write")
+	}
+
+	for _, item := range val {
+		if dst, err = e.appendValue(dst, item); err != nil {
+			return dst, err
+		}
+	}
+	return dst, nil
+}
+
+func (e Encoder) appendMap(dst []byte, val map[string]interface{}) ([]byte, error) {
+	val = e.skipUnsupported(val)
+	dst, err := appendSize(dst, len(val), TinyMapMarker, mapMarkers)
+	if err != nil {
+		return dst, errors.Wrap(err, "Map too long to write")
+	}
+
+	for k, v := range val {
+		if dst, err = appendString(dst, k); err != nil {
+			return dst, err
+		}
+		if dst, err = e.appendValue(dst, v); err != nil {
+			return dst, err
+		}
+	}
+	return dst, nil
+}
+
+func (e Encoder) appendStructure(dst []byte, val structures.Structure) ([]byte, error) {
+	fields := val.AllFields()
+	dst, err := appendSize(dst, len(fields), TinyStructMarker, structMarkers)
+	if err != nil {
+		return dst, errors.Wrap(err, "Structure too long to write")
+	}
+	dst = append(dst, byte(val.Signature()))
+
+	for _, field := range fields {
+		if dst, err = e.appendValue(dst, field); err != nil {
+			return dst, errors.Wrap(err, "An error occurred encoding a struct field")
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,207 @@
+package encoding
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/messages"
+)
+
+var appendEncodeValues = []interface{}{
+	nil,
+	true,
+	false,
+	0,
+	-16,
+	-17,
+	int8(-128),
+	127,
+	128,
+	int16(-129),
+	40000,
+	int64(-40000),
+	int64(math.MaxInt64),
+	int64(math.MinInt64),
+	uint32(7),
+	1.5,
+	float32(-2.25),
+	"",
+	"foo",
+	strings.Repeat("a", 300),
+	strings.Repeat("b", 100000),
+	[]interface{}{1, "two", 3.0, nil, []interface{}{true}},
+	[]string{"a", "b"},
+	map[string]interface{}{"key": []interface{}{1, 2, 3}},
+	graph.Node{NodeIdentity: 1, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "foo"}},
+	messages.NewRunMessage("RETURN $x", map[string]interface{}{"x": 1}),
+	RawValue{TinyStringMarker + 1, 'a'},
+	(*bool)(nil),
+	&appendBool,
+	sql.NullBool{Bool: true, Valid: true},
+}
+
+var appendBool = true
+
+func TestAppendEncode(t *testing.T) {
+	for _, val := range appendEncodeValues {
+		expected, err := Marshal(val)
+		if err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", val, err)
+		}
+
+		prefix := []byte{0x01, 0x02}
+		encoded, err := AppendEncode(prefix, val)
+		if err != nil {
+			t.Fatalf("An error occurred append encoding %#v: %s", val, err)
+		}
+
+		if !bytes.Equal(encoded[:2], prefix) {
+			t.Fatalf("Expected existing bytes to be kept. Got: %#v", encoded[:2])
+		}
+		if !bytes.Equal(encoded[2:], expected) {
+			t.Fatalf("Append encoding of %T differs from Encode. Expected %#v. Got: %#v", val, expected, encoded[2:])
+		}
+	}
+}
+
+func TestAppendEncode_Error(t *testing.T) {
+	dst := []byte{0x01}
+	encoded, err := AppendEncode(dst, []interface{}{1, make(chan int)})
+	if err == nil {
+		t.Fatal("Expected error append encoding unencodable value")
+	}
+	if !bytes.Equal(encoded, dst) {
+		t.Fatalf("Expected dst to be returned unchanged on error. Got: %#v", encoded)
+	}
+}
+
+func TestEncoder_AppendEncodeOptions(t *testing.T) {
+	type person struct {
+		Name string `bolt:"name"`
+	}
+	type empty struct {
+		name string
+	}
+
+	tests := []struct {
+		configure func(*Encoder)
+		val       interface{}
+		wantErr   bool
+	}{
+		{func(e *Encoder) { e.FixedWidthInts = true }, []interface{}{1, -100, 40000}, false},
+		{func(e *Encoder) { e.StringifyStringers = true }, testStringer{name: "foo"}, false},
+		{func(e *Encoder) { e.EncodeStructs = true }, []interface{}{person{Name: "foo"}, &person{Name: "bar"}}, false},
+		{func(e *Encoder) { e.EncodeStructs = true }, empty{}, false},
+		{func(e *Encoder) { e.EncodeStructs, e.ErrorOnEmptyStruct = true, true }, empty{}, true},
+		{func(e *Encoder) { e.RejectNonFinite = true }, []interface{}{1.5}, false},
+		{func(e *Encoder) { e.RejectNonFinite = true }, []interface{}{math.Inf(1)}, true},
+		{func(e *Encoder) { e.OnUnsupportedType = UnsupportedTypeEncodeNil }, []interface{}{1, make(chan int)}, false},
+		{func(e *Encoder) { e.OnUnsupportedType = UnsupportedTypeSkip }, map[string]interface{}{"a": 1, "b": make(chan int)}, false},
+		{func(e *Encoder) { e.OnUnsupportedType = UnsupportedTypeSkip }, []interface{}{1, make(chan int)}, false},
+	}
+
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, math.MaxUint16)
+		test.configure(&enc)
+
+		encodeErr := enc.Encode(test.val)
+		encoded, err := enc.AppendEncode(nil, test.val)
+		if test.wantErr {
+			if encodeErr == nil || err == nil {
+				t.Fatalf("Expected error encoding %#v. Encode: %v. AppendEncode: %v", test.val, encodeErr, err)
+			}
+			continue
+		}
+		if encodeErr != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", test.val, encodeErr)
+		}
+		if err != nil {
+			t.Fatalf("An error occurred append encoding %#v: %s", test.val, err)
+		}
+		if !bytes.Equal(encoded, buf.Bytes()) {
+			t.Fatalf("Append encoding of %#v differs from Encode. Expected %#v. Got: %#v", test.val, buf.Bytes(), encoded)
+		}
+
+		size, err := enc.EncodedSize(test.val)
+		if err != nil {
+			t.Fatalf("An error occurred getting size of %#v: %s", test.val, err)
+		}
+		// Less the chunk header and end marker
+		if size != buf.Len()-4 {
+			t.Fatalf("Unexpected size of %#v. Expected: %d. Got: %d", test.val, buf.Len()-4, size)
+		}
+	}
+}
+
+func TestEncoder_AppendEncodeChunkSize(t *testing.T) {
+	// Over a full chunk, so it's split in two
+	val := []interface{}{strings.Repeat("a", 70000), 1}
+	buf := &bytes.Buffer{}
+	enc := NewEncoder(buf, math.MaxUint16)
+	if err := enc.Encode(val); err != nil {
+		t.Fatalf("An error occurred encoding: %s", err)
+	}
+
+	encoded, err := enc.AppendEncode(nil, val)
+	if err != nil {
+		t.Fatalf("An error occurred append encoding: %s", err)
+	}
+	if !bytes.Equal(encoded, buf.Bytes()) {
+		t.Fatal("Append encoding over 64KB differs from Encode")
+	}
+
+	for _, chunkSize := range []uint16{1000, 7} {
+		encoded, err := NewEncoder(nil, chunkSize).AppendEncode(nil, val)
+		if err != nil {
+			t.Fatalf("An error occurred append encoding with chunk size %d: %s", chunkSize, err)
+		}
+		if size := binary.BigEndian.Uint16(encoded); size != chunkSize {
+			t.Fatalf("Expected chunks of %d bytes. Got: %d", chunkSize, size)
+		}
+
+		decoded, err := NewDecoder(bytes.NewReader(encoded)).Decode()
+		if err != nil {
+			t.Fatalf("An error occurred decoding chunk size %d: %s", chunkSize, err)
+		}
+		if !reflect.DeepEqual(decoded, []interface{}{val[0], int64(1)}) {
+			t.Fatalf("Unexpected value decoded with chunk size %d", chunkSize)
+		}
+	}
+}
+
+func benchmarkValue() interface{} {
+	return messages.NewRunMessage("MATCH (n) WHERE n.id = $id RETURN n", map[string]interface{}{
+		"id": 12345,
+	})
+}
+
+func BenchmarkEncode(b *testing.B) {
+	val := benchmarkValue()
+	buf := &bytes.Buffer{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := NewEncoder(buf, math.MaxUint16).Encode(val); err != nil {
+			b.Fatalf("An error occurred encoding: %s", err)
+		}
+	}
+}
+
+func BenchmarkAppendEncode(b *testing.B) {
+	val := benchmarkValue()
+	dst := make([]byte, 0, 1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var err error
+		if dst, err = AppendEncode(dst[:0], val); err != nil {
+			b.Fatalf("An error occurred append encoding: %s", err)
+		}
+	}
+}
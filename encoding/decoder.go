@@ -3,9 +3,12 @@ package encoding
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
 	"io"
+	"strconv"
 
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures"
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/messages"
 )
@@ -21,6 +24,21 @@ import (
 type Decoder struct {
 	r   io.Reader
 	buf *bytes.Buffer
+	// NumbersAsJSONNumber decodes integers and floats as json.Number,
+	// to avoid losing precision when re-serializing to JSON.  Identities
+	// and other integer fields of graph structures are still int64.
+	NumbersAsJSONNumber bool
+	// ErrorOnUnknownStructure errors when decoding a structure with a signature
+	// the driver doesn't know.  By default, they are decoded as a
+	// structures.GenericStructure, so the rest of the result still decodes.
+	// Erroring is opt in, rather than the default, since unknown structures
+	// have always been decoded as a GenericStructure, and messages sent by
+	// the client, such as RUN, rely on it to be decoded.
+	ErrorOnUnknownStructure bool
+	// RecordFields, if not nil, is reused for the fields of a decoded record
+	// message.  It's only reallocated if the record has more fields than
+	// its capacity.  Values within the fields are always newly allocated.
+	RecordFields []interface{}
 }
 
 // NewDecoder Creates a new Decoder object
@@ -41,8 +59,8 @@ func (d Decoder) read() (*bytes.Buffer, error) {
 	output := &bytes.Buffer{}
 	for {
 		lengthBytes := make([]byte, 2)
-		if numRead, err := d.r.Read(lengthBytes); numRead != 2 {
-			return nil, errors.Wrap(err, "Couldn't read expected bytes for message length. Read: %d Expected: 2.", numRead)
+		if numRead, err := io.ReadFull(d.r, lengthBytes); err != nil {
+			return nil, errors.Wrap(unexpectedEOF(err), "Couldn't read expected bytes for message length. Read: %d Expected: 2.", numRead)
 		}
 
 		// Chunk header contains length of current message
@@ -73,7 +91,7 @@ func (d Decoder) readData(messageLen uint16) ([]byte, error) {
 		data := make([]byte, messageLen-totalRead)
 		numRead, err := d.r.Read(data)
 		if err != nil {
-			return nil, errors.Wrap(err, "An error occurred reading from stream")
+			return nil, errors.Wrap(unexpectedEOF(err), "An error occurred reading from stream")
 		} else if numRead == 0 {
 			return nil, errors.Wrap(err, "Couldn't read expected bytes for message. Read: %d Expected: %d.", totalRead, messageLen)
 		}
@@ -88,6 +106,15 @@ func (d Decoder) readData(messageLen uint16) ([]byte, error) {
 	return output, nil
 }
 
+// unexpectedEOF converts an EOF into an unexpected EOF, since the
+// stream should never end while a message is expected
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
 // Decode decodes the stream to an object
 func (d Decoder) Decode() (interface{}, error) {
 	data, err := d.read()
@@ -98,6 +125,17 @@ func (d Decoder) Decode() (interface{}, error) {
 	return d.decode(data)
 }
 
+// DecodeWithBytes decodes the next message like Decode, and also returns
+// the exact bytes read from the stream for it, including the chunk headers
+// and end marker.  Unmarshal decodes the bytes to the same value, so they're
+// useful for debugging unexpected values and writing regression fixtures.
+func (d Decoder) DecodeWithBytes() (interface{}, []byte, error) {
+	raw := &bytes.Buffer{}
+	d.r = io.TeeReader(d.r, raw)
+	v, err := d.Decode()
+	return v, raw.Bytes(), err
+}
+
 func (d Decoder) decode(buffer *bytes.Buffer) (interface{}, error) {
 
 	marker, err := buffer.ReadByte()
@@ -127,29 +165,29 @@ func (d Decoder) decode(buffer *bytes.Buffer) (interface{}, error) {
 
 	// INT
 	case markerInt >= -16 && markerInt <= 127:
-		return int64(int8(marker)), nil
+		return d.number(int64(int8(marker)), nil)
 	case marker == Int8Marker:
 		var out int8
-		err := binary.Read(buffer, binary.BigEndian, &out)
-		return int64(out), err
+		err := readFixed(buffer, "INT_8", &out)
+		return d.number(int64(out), err)
 	case marker == Int16Marker:
 		var out int16
-		err := binary.Read(buffer, binary.BigEndian, &out)
-		return int64(out), err
+		err := readFixed(buffer, "INT_16", &out)
+		return d.number(int64(out), err)
 	case marker == Int32Marker:
 		var out int32
-		err := binary.Read(buffer, binary.BigEndian, &out)
-		return int64(out), err
+		err := readFixed(buffer, "INT_32", &out)
+		return d.number(int64(out), err)
 	case marker == Int64Marker:
 		var out int64
-		err := binary.Read(buffer, binary.BigEndian, &out)
-		return int64(out), err
+		err := readFixed(buffer, "INT_64", &out)
+		return d.number(int64(out), err)
 
 	// FLOAT
 	case marker == FloatMarker:
 		var out float64
-		err := binary.Read(buffer, binary.BigEndian, &out)
-		return out, err
+		err := readFixed(buffer, "FLOAT_64", &out)
+		return d.number(out, err)
 
 	// STRING
 	case marker >= TinyStringMarker && marker <= TinyStringMarker+0x0F:
@@ -159,13 +197,13 @@ func (d Decoder) decode(buffer *bytes.Buffer) (interface{}, error) {
 		}
 		return string(buffer.Next(size)), nil
 	case marker == String8Marker:
-		var size int8
+		var size uint8
 		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
 			return nil, errors.Wrap(err, "An error occurred reading string size")
 		}
 		return string(buffer.Next(int(size))), nil
 	case marker == String16Marker:
-		var size int16
+		var size uint16
 		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
 			return nil, errors.Wrap(err, "An error occurred reading string size")
 		}
@@ -178,40 +216,25 @@ func (d Decoder) decode(buffer *bytes.Buffer) (interface{}, error) {
 		return string(buffer.Next(int(size))), nil
 
 	// SLICE
-	case marker >= TinySliceMarker && marker <= TinySliceMarker+0x0F:
-		size := int(marker) - int(TinySliceMarker)
-		return d.decodeSlice(buffer, size)
-	case marker == Slice8Marker:
-		var size int8
-		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
-			return nil, errors.Wrap(err, "An error occurred reading slice size")
-		}
-		return d.decodeSlice(buffer, int(size))
-	case marker == Slice16Marker:
-		var size int16
-		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
-			return nil, errors.Wrap(err, "An error occurred reading slice size")
-		}
-		return d.decodeSlice(buffer, int(size))
-	case marker == Slice32Marker:
-		var size int32
-		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
-			return nil, errors.Wrap(err, "An error occurred reading slice size")
+	case isSliceMarker(marker):
+		size, err := readSliceSize(buffer, marker)
+		if err != nil {
+			return nil, err
 		}
-		return d.decodeSlice(buffer, int(size))
+		return d.decodeSlice(buffer, size)
 
 	// MAP
 	case marker >= TinyMapMarker && marker <= TinyMapMarker+0x0F:
 		size := int(marker) - int(TinyMapMarker)
 		return d.decodeMap(buffer, size)
 	case marker == Map8Marker:
-		var size int8
+		var size uint8
 		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
 			return nil, errors.Wrap(err, "An error occurred reading map size")
 		}
 		return d.decodeMap(buffer, int(size))
 	case marker == Map16Marker:
-		var size int16
+		var size uint16
 		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
 			return nil, errors.Wrap(err, "An error occurred reading map size")
 		}
@@ -228,13 +251,13 @@ func (d Decoder) decode(buffer *bytes.Buffer) (interface{}, error) {
 		size := int(marker) - int(TinyStructMarker)
 		return d.decodeStruct(buffer, size)
 	case marker == Struct8Marker:
-		var size int8
+		var size uint8
 		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
 			return nil, errors.Wrap(err, "An error occurred reading struct size")
 		}
 		return d.decodeStruct(buffer, int(size))
 	case marker == Struct16Marker:
-		var size int16
+		var size uint16
 		if err := binary.Read(buffer, binary.BigEndian, &size); err != nil {
 			return nil, errors.Wrap(err, "An error occurred reading struct size")
 		}
@@ -246,17 +269,103 @@ func (d Decoder) decode(buffer *bytes.Buffer) (interface{}, error) {
 
 }
 
+// readFixed reads the fixed size value following a marker,
+// erroring if the message ends before all of its bytes
+func readFixed(buffer *bytes.Buffer, name string, out interface{}) error {
+	if size := binary.Size(out); buffer.Len() < size {
+		return errors.New("Truncated %s: expected %d bytes after marker but only %d remain", name, size, buffer.Len())
+	}
+	return binary.Read(buffer, binary.BigEndian, out)
+}
+
+// number converts a decoded integer or float to a json.Number,
+// if the decoder is set to decode numbers that way
+func (d Decoder) number(val interface{}, err error) (interface{}, error) {
+	if err != nil || !d.NumbersAsJSONNumber {
+		return val, err
+	}
+
+	switch val := val.(type) {
+	case int64:
+		return json.Number(strconv.FormatInt(val, 10)), nil
+	case float64:
+		return json.Number(strconv.FormatFloat(val, 'g', -1, 64)), nil
+	default:
+		return val, nil
+	}
+}
+
+// decodeStructInt decodes an integer field of a structure, which is
+// always an int64 regardless of NumbersAsJSONNumber
+func (d Decoder) decodeStructInt(buffer *bytes.Buffer, name string) (int64, error) {
+	d.NumbersAsJSONNumber = false
+	valInt, err := d.decode(buffer)
+	if err != nil {
+		return 0, err
+	}
+
+	val, ok := valInt.(int64)
+	if !ok {
+		return 0, errors.New("Expected: %s int64, but got %T %+v", name, valInt, valInt)
+	}
+	return val, nil
+}
+
+func isSliceMarker(marker byte) bool {
+	return (marker >= TinySliceMarker && marker <= TinySliceMarker+0x0F) ||
+		marker == Slice8Marker || marker == Slice16Marker || marker == Slice32Marker
+}
+
+// readSliceSize reads the size of the slice with the given marker.  Sizes
+// are unsigned, so a list of 200 items has a Slice8 size of 0xC8.
+func readSliceSize(buffer *bytes.Buffer, marker byte) (int, error) {
+	var size int64
+	var err error
+	switch marker {
+	case Slice8Marker:
+		var size8 uint8
+		err = binary.Read(buffer, binary.BigEndian, &size8)
+		size = int64(size8)
+	case Slice16Marker:
+		var size16 uint16
+		err = binary.Read(buffer, binary.BigEndian, &size16)
+		size = int64(size16)
+	case Slice32Marker:
+		var size32 int32
+		err = binary.Read(buffer, binary.BigEndian, &size32)
+		size = int64(size32)
+	default:
+		size = int64(marker) - int64(TinySliceMarker)
+	}
+
+	if err != nil {
+		return 0, errors.Wrap(err, "An error occurred reading slice size")
+	}
+	return int(size), nil
+}
+
 func (d Decoder) decodeSlice(buffer *bytes.Buffer, size int) ([]interface{}, error) {
-	slice := make([]interface{}, size)
+	return d.decodeSliceInto(nil, buffer, size)
+}
+
+// decodeSliceInto decodes a slice into dst, only allocating
+// a new slice if dst doesn't have the capacity for it
+func (d Decoder) decodeSliceInto(dst []interface{}, buffer *bytes.Buffer, size int) ([]interface{}, error) {
+	if cap(dst) >= size {
+		dst = dst[:size]
+	} else {
+		dst = make([]interface{}, size)
+	}
+
 	for i := 0; i < size; i++ {
 		item, err := d.decode(buffer)
 		if err != nil {
 			return nil, err
 		}
-		slice[i] = item
+		dst[i] = item
 	}
 
-	return slice, nil
+	return dst, nil
 }
 
 func (d Decoder) decodeMap(buffer *bytes.Buffer, size int) (map[string]interface{}, error) {
@@ -266,15 +375,16 @@ func (d Decoder) decodeMap(buffer *bytes.Buffer, size int) (map[string]interface
 		if err != nil {
 			return nil, err
 		}
+		// Neo4j only sends string keys, so anything else means a corrupt stream
+		key, ok := keyInt.(string)
+		if !ok {
+			return nil, errors.New("Encountered non-string map key: %T with value %+v", keyInt, keyInt)
+		}
+
 		val, err := d.decode(buffer)
 		if err != nil {
 			return nil, err
 		}
-
-		key, ok := keyInt.(string)
-		if !ok {
-			return nil, errors.New("Unexpected key type: %T with value %+v", keyInt, keyInt)
-		}
 		mapp[key] = val
 	}
 
@@ -314,18 +424,32 @@ func (d Decoder) decodeStruct(buffer *bytes.Buffer, size int) (interface{}, erro
 	case messages.ResetMessageSignature:
 		return d.decodeResetMessage(buffer)
 	default:
-		return nil, errors.New("Unrecognized type decoding struct with signature %x", signature)
+		if d.ErrorOnUnknownStructure {
+			return nil, errors.New("Unknown structure signature: %x", signature)
+		}
+		return d.decodeGenericStructure(buffer, signature, size)
 	}
 }
 
+// decodeGenericStructure keeps the raw fields of a structure with an unknown
+// signature, so it can be re-encoded without losing anything
+func (d Decoder) decodeGenericStructure(buffer *bytes.Buffer, signature byte, size int) (structures.GenericStructure, error) {
+	fields, err := d.decodeSlice(buffer, size)
+	if err != nil {
+		return structures.GenericStructure{}, errors.Wrap(err, "An error occurred decoding struct with signature %x", signature)
+	}
+
+	return structures.GenericStructure{StructSignature: int(signature), Fields: fields}, nil
+}
+
 func (d Decoder) decodeNode(buffer *bytes.Buffer) (graph.Node, error) {
 	node := graph.Node{}
 
-	nodeIdentityInt, err := d.decode(buffer)
+	var err error
+	node.NodeIdentity, err = d.decodeStructInt(buffer, "NodeIdentity")
 	if err != nil {
 		return node, err
 	}
-	node.NodeIdentity = nodeIdentityInt.(int64)
 
 	labelInt, err := d.decode(buffer)
 	if err != nil {
@@ -356,23 +480,21 @@ func (d Decoder) decodeNode(buffer *bytes.Buffer) (graph.Node, error) {
 func (d Decoder) decodeRelationship(buffer *bytes.Buffer) (graph.Relationship, error) {
 	rel := graph.Relationship{}
 
-	relIdentityInt, err := d.decode(buffer)
+	var err error
+	rel.RelIdentity, err = d.decodeStructInt(buffer, "RelIdentity")
 	if err != nil {
 		return rel, err
 	}
-	rel.RelIdentity = relIdentityInt.(int64)
 
-	startNodeIdentityInt, err := d.decode(buffer)
+	rel.StartNodeIdentity, err = d.decodeStructInt(buffer, "StartNodeIdentity")
 	if err != nil {
 		return rel, err
 	}
-	rel.StartNodeIdentity = startNodeIdentityInt.(int64)
 
-	endNodeIdentityInt, err := d.decode(buffer)
+	rel.EndNodeIdentity, err = d.decodeStructInt(buffer, "EndNodeIdentity")
 	if err != nil {
 		return rel, err
 	}
-	rel.EndNodeIdentity = endNodeIdentityInt.(int64)
 
 	var ok bool
 	typeInt, err := d.decode(buffer)
@@ -425,7 +547,10 @@ func (d Decoder) decodePath(buffer *bytes.Buffer) (graph.Path, error) {
 		return path, err
 	}
 
-	seqInt, err := d.decode(buffer)
+	// The sequence is structural, so always decode it as ints
+	seqDecoder := d
+	seqDecoder.NumbersAsJSONNumber = false
+	seqInt, err := seqDecoder.decode(buffer)
 	if err != nil {
 		return path, err
 	}
@@ -441,11 +566,11 @@ func (d Decoder) decodePath(buffer *bytes.Buffer) (graph.Path, error) {
 func (d Decoder) decodeUnboundRelationship(buffer *bytes.Buffer) (graph.UnboundRelationship, error) {
 	rel := graph.UnboundRelationship{}
 
-	relIdentityInt, err := d.decode(buffer)
+	var err error
+	rel.RelIdentity, err = d.decodeStructInt(buffer, "RelIdentity")
 	if err != nil {
 		return rel, err
 	}
-	rel.RelIdentity = relIdentityInt.(int64)
 
 	var ok bool
 	typeInt, err := d.decode(buffer)
@@ -470,6 +595,26 @@ func (d Decoder) decodeUnboundRelationship(buffer *bytes.Buffer) (graph.UnboundR
 }
 
 func (d Decoder) decodeRecordMessage(buffer *bytes.Buffer) (messages.RecordMessage, error) {
+	if d.RecordFields != nil {
+		marker, err := buffer.ReadByte()
+		if err != nil {
+			return messages.RecordMessage{}, errors.Wrap(err, "Error reading marker")
+		}
+		if !isSliceMarker(marker) {
+			return messages.RecordMessage{}, errors.New("Expected: Fields []interface{}, but got marker %x", marker)
+		}
+
+		size, err := readSliceSize(buffer, marker)
+		if err != nil {
+			return messages.RecordMessage{}, err
+		}
+		fields, err := d.decodeSliceInto(d.RecordFields, buffer, size)
+		if err != nil {
+			return messages.RecordMessage{}, err
+		}
+		return messages.NewRecordMessage(fields), nil
+	}
+
 	fieldsInt, err := d.decode(buffer)
 	if err != nil {
 		return messages.RecordMessage{}, err
@@ -0,0 +1,487 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
+)
+
+func TestDecoder_NodeLabelOrder(t *testing.T) {
+	labels := []string{"Person", "Employee", "Admin", "Auditor"}
+	encoded, err := Marshal(graph.Node{
+		NodeIdentity: 1,
+		Labels:       labels,
+		Properties:   map[string]interface{}{},
+	})
+	if err != nil {
+		t.Fatalf("An error occurred encoding node: %s", err)
+	}
+
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding node: %s", err)
+	}
+
+	node, ok := decoded.(graph.Node)
+	if !ok {
+		t.Fatalf("Expected node to be decoded. Got: %#v", decoded)
+	}
+	if !reflect.DeepEqual(node.Labels, labels) {
+		t.Fatalf("Node labels not in wire order. Expected %#v. Got: %#v", labels, node.Labels)
+	}
+}
+
+func TestDecoder_Bool(t *testing.T) {
+	decoded, err := Unmarshal([]byte{0x00, 0x01, TrueMarker, 0x00, 0x00})
+	if err != nil {
+		t.Fatalf("An error occurred decoding true: %s", err)
+	}
+	if val, ok := decoded.(bool); !ok || !val {
+		t.Fatalf("Expected true to decode to bool true. Got: %#v", decoded)
+	}
+
+	decoded, err = Unmarshal([]byte{0x00, 0x01, FalseMarker, 0x00, 0x00})
+	if err != nil {
+		t.Fatalf("An error occurred decoding false: %s", err)
+	}
+	if val, ok := decoded.(bool); !ok || val {
+		t.Fatalf("Expected false to decode to bool false. Got: %#v", decoded)
+	}
+}
+
+func TestDecoder_TinyIntBoundaries(t *testing.T) {
+	tests := []struct {
+		marker   byte
+		expected interface{}
+	}{
+		{0xF0, int64(-16)},
+		{0xFF, int64(-1)},
+		{0x00, int64(0)},
+		{0x7F, int64(127)},
+		{0x80, ""},
+	}
+
+	for _, test := range tests {
+		decoded, err := Unmarshal([]byte{0x00, 0x01, test.marker, 0x00, 0x00})
+		if err != nil {
+			t.Fatalf("An error occurred decoding marker %x: %s", test.marker, err)
+		}
+		if !reflect.DeepEqual(decoded, test.expected) {
+			t.Fatalf("Unexpected value decoding marker %x. Expected %#v. Got: %#v", test.marker, test.expected, decoded)
+		}
+	}
+
+	// Just below the tiny int range is not a marker, rather than -17
+	if decoded, err := Unmarshal([]byte{0x00, 0x01, 0xEF, 0x00, 0x00}); err == nil {
+		t.Fatalf("Expected error decoding marker ef. Got: %#v", decoded)
+	}
+}
+
+func TestDecoder_UnsignedSizes(t *testing.T) {
+	// Sizes over the max of a signed int8 and int16
+	for _, size := range []int{200, 40000} {
+		list := make([]interface{}, size)
+		m := make(map[string]interface{}, size)
+		for i := range list {
+			list[i] = int64(i)
+			m[strconv.Itoa(i)] = int64(i)
+		}
+
+		for _, val := range []interface{}{list, strings.Repeat("a", size), m} {
+			encoded, err := Marshal(val)
+			if err != nil {
+				t.Fatalf("An error occurred encoding %T of size %d: %s", val, size, err)
+			}
+			decoded, err := Unmarshal(encoded)
+			if err != nil {
+				t.Fatalf("An error occurred decoding %T of size %d: %s", val, size, err)
+			}
+			if !reflect.DeepEqual(decoded, val) {
+				t.Fatalf("Unexpected decoded %T of size %d", val, size)
+			}
+		}
+	}
+}
+
+func TestDecoder_UnrecognizedMarker(t *testing.T) {
+	// 0xF0 through 0xFF are negative TINY_INTs, so use markers
+	// that are undefined in PackStream instead
+	for _, marker := range []byte{0xC4, 0xD3, 0xE0, 0xEF} {
+		decoded, err := Unmarshal([]byte{0x00, 0x01, marker, 0x00, 0x00})
+		if err == nil {
+			t.Fatalf("Expected error decoding undefined marker %x. Got: %#v", marker, decoded)
+		}
+		if !strings.Contains(err.Error(), fmt.Sprintf("Unrecognized marker byte!: %x", marker)) {
+			t.Fatalf("Expected unrecognized marker error including byte %x. Got: %s", marker, err)
+		}
+	}
+
+	decoded, err := Unmarshal([]byte{0x00, 0x01, 0xFF, 0x00, 0x00})
+	if err != nil {
+		t.Fatalf("An error occurred decoding TINY_INT -1: %s", err)
+	}
+	if decoded != int64(-1) {
+		t.Fatalf("Expected 0xFF to decode as TINY_INT -1. Got: %#v", decoded)
+	}
+}
+
+func TestDecoder_GenericStructureRoundTrip(t *testing.T) {
+	// Structure with made up signature 0x7A and fields: 1, "a", [true], {"k": nil}
+	encoded := []byte{
+		0x00, 0x0B,
+		TinyStructMarker + 4, 0x7A,
+		0x01,
+		TinyStringMarker + 1, 'a',
+		TinySliceMarker + 1, TrueMarker,
+		TinyMapMarker + 1, TinyStringMarker + 1, 'k', NilMarker,
+		0x00, 0x00,
+	}
+
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding unknown structure: %s", err)
+	}
+
+	structure, ok := decoded.(structures.GenericStructure)
+	if !ok {
+		t.Fatalf("Expected generic structure to be decoded. Got: %#v", decoded)
+	}
+	if structure.Signature() != 0x7A {
+		t.Fatalf("Unexpected structure signature: %x", structure.Signature())
+	}
+
+	reencoded, err := Marshal(structure)
+	if err != nil {
+		t.Fatalf("An error occurred re-encoding unknown structure: %s", err)
+	}
+	if !bytes.Equal(reencoded, encoded) {
+		t.Fatalf("Expected unknown structure to re-encode identically. Expected %x. Got: %x", encoded, reencoded)
+	}
+}
+
+func TestDecoder_NonStringMapKey(t *testing.T) {
+	// {1: "a"}
+	_, err := Unmarshal([]byte{0x00, 0x04, TinyMapMarker + 1, 0x01, TinyStringMarker + 1, 'a', 0x00, 0x00})
+	if err == nil {
+		t.Fatal("Expected error decoding map with an int key")
+	}
+	if !strings.Contains(err.Error(), "non-string map key") {
+		t.Fatalf("Expected non-string map key error. Got: %s", err)
+	}
+}
+
+func TestDecoder_NumbersAsJSONNumber(t *testing.T) {
+	encoded, err := Marshal([]interface{}{int64(9007199254740993), 1.5, int64(-3)})
+	if err != nil {
+		t.Fatalf("An error occurred encoding numbers: %s", err)
+	}
+
+	decoder := NewDecoder(bytes.NewBuffer(encoded))
+	decoder.NumbersAsJSONNumber = true
+	decoded, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("An error occurred decoding numbers: %s", err)
+	}
+
+	expected := []interface{}{json.Number("9007199254740993"), json.Number("1.5"), json.Number("-3")}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("Unexpected decoded numbers. Expected %#v. Got: %#v", expected, decoded)
+	}
+}
+
+func TestDecoder_NumbersAsJSONNumberNode(t *testing.T) {
+	encoded, err := Marshal(graph.Node{
+		NodeIdentity: 7,
+		Labels:       []string{"Person"},
+		Properties:   map[string]interface{}{"age": int64(30)},
+	})
+	if err != nil {
+		t.Fatalf("An error occurred encoding node: %s", err)
+	}
+
+	decoder := NewDecoder(bytes.NewBuffer(encoded))
+	decoder.NumbersAsJSONNumber = true
+	decoded, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("An error occurred decoding node: %s", err)
+	}
+
+	node, ok := decoded.(graph.Node)
+	if !ok || node.NodeIdentity != 7 || node.Properties["age"] != json.Number("30") {
+		t.Fatalf("Expected node identity to stay int64 and properties to be json.Number. Got: %#v", decoded)
+	}
+}
+
+func TestDecoder_RelationshipTypedProperties(t *testing.T) {
+	encoded, err := Marshal(graph.Relationship{
+		RelIdentity:       3,
+		StartNodeIdentity: 1,
+		EndNodeIdentity:   2,
+		Type:              "KNOWS",
+		Properties:        map[string]interface{}{"since": int64(2001), "weight": 0.5, "mutual": true, "via": "work"},
+	})
+	if err != nil {
+		t.Fatalf("An error occurred encoding relationship: %s", err)
+	}
+
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding relationship: %s", err)
+	}
+	rel, ok := decoded.(graph.Relationship)
+	if !ok {
+		t.Fatalf("Expected relationship to be decoded. Got: %#v", decoded)
+	}
+
+	if since, err := rel.GetInt("since"); err != nil || since != 2001 {
+		t.Fatalf("Unexpected int property. Got: %d, %v", since, err)
+	}
+	if weight, err := rel.GetFloat("weight"); err != nil || weight != 0.5 {
+		t.Fatalf("Unexpected float property. Got: %f, %v", weight, err)
+	}
+	if mutual, err := rel.GetBool("mutual"); err != nil || !mutual {
+		t.Fatalf("Unexpected bool property. Got: %t, %v", mutual, err)
+	}
+	if via, err := rel.GetString("via"); err != nil || via != "work" {
+		t.Fatalf("Unexpected string property. Got: %s, %v", via, err)
+	}
+	if _, err := rel.GetString("since"); err == nil {
+		t.Fatal("Expected error getting int property as a string")
+	}
+	if _, err := rel.GetBool("missing"); err == nil {
+		t.Fatal("Expected error getting missing property")
+	}
+
+	var props struct {
+		Since  int64   `json:"since"`
+		Weight float64 `json:"weight"`
+		Via    string  `json:"via"`
+	}
+	if err := rel.PropertiesAs(&props); err != nil {
+		t.Fatalf("An error occurred getting properties as struct: %s", err)
+	}
+	if props.Since != 2001 || props.Weight != 0.5 || props.Via != "work" {
+		t.Fatalf("Unexpected properties struct: %#v", props)
+	}
+}
+
+func TestDecoder_RelationshipEmptyProperties(t *testing.T) {
+	// Both an empty and a nil properties map are sent as an empty map
+	for _, properties := range []map[string]interface{}{{}, nil} {
+		encoded, err := Marshal(graph.Relationship{
+			RelIdentity:       3,
+			StartNodeIdentity: 1,
+			EndNodeIdentity:   2,
+			Type:              "KNOWS",
+			Properties:        properties,
+		})
+		if err != nil {
+			t.Fatalf("An error occurred encoding relationship: %s", err)
+		}
+
+		decoded, err := Unmarshal(encoded)
+		if err != nil {
+			t.Fatalf("An error occurred decoding relationship: %s", err)
+		}
+		rel, ok := decoded.(graph.Relationship)
+		if !ok {
+			t.Fatalf("Expected relationship to be decoded. Got: %#v", decoded)
+		}
+		if rel.Properties == nil || len(rel.Properties) != 0 {
+			t.Fatalf("Expected non-nil empty properties. Got: %#v", rel.Properties)
+		}
+	}
+}
+
+func TestDecoder_TruncatedInts(t *testing.T) {
+	for _, marker := range []byte{Int8Marker, Int16Marker, Int32Marker, Int64Marker, FloatMarker} {
+		// Only 0 bytes after INT_8, and 1 byte after the rest
+		message := []byte{marker}
+		if marker != Int8Marker {
+			message = append(message, 0x01)
+		}
+
+		encoded := append([]byte{0x00, byte(len(message))}, message...)
+		encoded = append(encoded, EndMessage...)
+
+		_, err := Unmarshal(encoded)
+		if err == nil {
+			t.Fatalf("Expected error decoding truncated marker %x", marker)
+		}
+		if !strings.Contains(err.Error(), "Truncated") {
+			t.Fatalf("Expected truncation error decoding marker %x. Got: %s", marker, err)
+		}
+	}
+}
+
+func TestDecoder_TruncatedStream(t *testing.T) {
+	// The chunk claims more bytes than the stream has, which
+	// must error rather than block waiting for more
+	_, err := NewDecoder(bytes.NewReader([]byte{0x00, 0x05, Int32Marker, 0x01})).Decode()
+	if errors.Cause(err) != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected unexpected EOF decoding truncated stream. Got: %#v", err)
+	}
+}
+
+func TestDecoder_ErrorOnUnknownStructure(t *testing.T) {
+	// [1, Structure with made up signature 0x7A and field 1]
+	encoded := []byte{0x00, 0x05, TinySliceMarker + 2, 0x01, TinyStructMarker + 1, 0x7A, 0x01, 0x00, 0x00}
+
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding unknown structure: %s", err)
+	}
+	expected := []interface{}{int64(1), structures.GenericStructure{StructSignature: 0x7A, Fields: []interface{}{int64(1)}}}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("Expected unknown structure placeholder by default. Expected %#v. Got: %#v", expected, decoded)
+	}
+
+	decoder := NewDecoder(bytes.NewBuffer(encoded))
+	decoder.ErrorOnUnknownStructure = true
+	if _, err := decoder.Decode(); err == nil || !strings.Contains(err.Error(), "Unknown structure signature: 7a") {
+		t.Fatalf("Expected unknown structure error. Got: %v", err)
+	}
+}
+
+func TestDecoder_NestedCollections(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		expected interface{}
+	}{
+		{
+			val:      []interface{}{[]interface{}{1, 2}, []interface{}{3}},
+			expected: []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{int64(3)}},
+		},
+		{
+			val:      []interface{}{map[string]interface{}{"list": []interface{}{"a", []interface{}{true}}}},
+			expected: []interface{}{map[string]interface{}{"list": []interface{}{"a", []interface{}{true}}}},
+		},
+	}
+
+	for _, test := range tests {
+		encoded, err := Marshal(test.val)
+		if err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", test.val, err)
+		}
+
+		decoded, err := Unmarshal(encoded)
+		if err != nil {
+			t.Fatalf("An error occurred decoding %#v: %s", test.val, err)
+		}
+
+		if !reflect.DeepEqual(decoded, test.expected) {
+			t.Fatalf("Nested collection didn't round trip. Expected %#v. Got: %#v", test.expected, decoded)
+		}
+	}
+}
+
+func TestDecoder_NullsInList(t *testing.T) {
+	tests := [][]interface{}{
+		{int64(1), nil, int64(3)},
+		{nil, "a", nil, "b", nil},
+		{nil, nil, nil},
+	}
+
+	for _, list := range tests {
+		encoded, err := Marshal(list)
+		if err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", list, err)
+		}
+		decoded, err := Unmarshal(encoded)
+		if err != nil {
+			t.Fatalf("An error occurred decoding %#v: %s", list, err)
+		}
+		if !reflect.DeepEqual(decoded, list) {
+			t.Fatalf("Expected nulls to be kept in place. Expected %#v. Got: %#v", list, decoded)
+		}
+	}
+}
+
+func TestDecoder_NewPath(t *testing.T) {
+	// (a)-[:KNOWS]->(b)<-[:LIKES]-(c)
+	nodes := []graph.Node{
+		{NodeIdentity: 1, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "a"}},
+		{NodeIdentity: 2, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "b"}},
+		{NodeIdentity: 3, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "c"}},
+	}
+	rels := []graph.UnboundRelationship{
+		{RelIdentity: 4, Type: "KNOWS", Properties: map[string]interface{}{}},
+		{RelIdentity: 5, Type: "LIKES", Properties: map[string]interface{}{}},
+	}
+	path, err := graph.NewPath(nodes, rels, []int{1, 1, -2, 2})
+	if err != nil {
+		t.Fatalf("An error occurred creating path: %s", err)
+	}
+
+	encoded, err := Marshal(path)
+	if err != nil {
+		t.Fatalf("An error occurred encoding path: %s", err)
+	}
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding path: %s", err)
+	}
+	if !reflect.DeepEqual(decoded, path) {
+		t.Fatalf("Path didn't round trip. Expected %#v. Got: %#v", path, decoded)
+	}
+
+	for _, sequence := range [][]int{{1}, {0, 1}, {3, 1}, {-3, 1}, {1, 3}, {1, -1}} {
+		if _, err := graph.NewPath(nodes, rels, sequence); err == nil {
+			t.Fatalf("Expected error creating path with invalid sequence %v", sequence)
+		}
+	}
+}
+
+func TestDecoder_TruncatedFloat(t *testing.T) {
+	// A float marker followed by only 7 of its 8 bytes
+	encoded := []byte{0x00, 0x08, FloatMarker, 0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+
+	_, err := Unmarshal(encoded)
+	if err == nil {
+		t.Fatal("Expected error decoding truncated float")
+	}
+	if !strings.Contains(err.Error(), "Truncated FLOAT_64: expected 8 bytes after marker but only 7 remain") {
+		t.Fatalf("Expected descriptive truncated float error. Got: %s", err)
+	}
+}
+
+func TestDecoder_DecodeWithBytes(t *testing.T) {
+	first, err := Marshal(map[string]interface{}{"a": []interface{}{int64(1), "two"}})
+	if err != nil {
+		t.Fatalf("An error occurred encoding first value: %s", err)
+	}
+	// Small chunks, so the bytes for the second value span several chunks
+	buf := &bytes.Buffer{}
+	if err := NewEncoder(buf, 4).Encode(strings.Repeat("b", 20)); err != nil {
+		t.Fatalf("An error occurred encoding second value: %s", err)
+	}
+	second := buf.Bytes()
+
+	decoder := NewDecoder(bytes.NewReader(append(append([]byte{}, first...), second...)))
+	for _, expected := range [][]byte{first, second} {
+		value, raw, err := decoder.DecodeWithBytes()
+		if err != nil {
+			t.Fatalf("An error occurred decoding with bytes: %s", err)
+		}
+		if !bytes.Equal(raw, expected) {
+			t.Fatalf("Unexpected raw bytes. Expected: %#v. Got: %#v", expected, raw)
+		}
+
+		redecoded, err := Unmarshal(raw)
+		if err != nil {
+			t.Fatalf("An error occurred re-decoding raw bytes: %s", err)
+		}
+		if !reflect.DeepEqual(redecoded, value) {
+			t.Fatalf("Raw bytes decoded to a different value. Expected: %#v. Got: %#v", value, redecoded)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package encoding
 
 import (
+	"database/sql/driver"
 	"encoding/binary"
+	"fmt"
 	"io"
 	"math"
 	"reflect"
@@ -73,6 +75,29 @@ var (
 	EndMessage = []byte{byte(0x00), byte(0x00)}
 )
 
+// Parameterizer can be implemented by types to control how they are encoded.
+// When encoding a value implementing Parameterizer, the map returned from
+// Parameters is encoded in its place.
+type Parameterizer interface {
+	Parameters() map[string]interface{}
+}
+
+// Marshaler can be implemented by types to control how they are encoded.
+// When encoding a value implementing Marshaler, the value returned from
+// MarshalBolt is encoded in its place.  Unlike driver.Value, the value can
+// be anything the Encoder supports, such as a map or a list.
+type Marshaler interface {
+	MarshalBolt() (interface{}, error)
+}
+
+// RawValue is a value that has already been PackStream encoded.  It is
+// written to the stream verbatim, without any validation.
+//
+// Use with care: if the bytes are not exactly one complete encoded
+// value, the rest of the message will be misread by the server and the
+// connection will be out of sync.
+type RawValue []byte
+
 // Encoder encodes objects of different types to the given stream.
 // Attempts to support all builtin golang types, when it can be confidently
 // mapped to a data type from: http://alpha.neohq.net/docs/server-manual/bolt-serialization.html#bolt-packstream-structures
@@ -81,12 +106,66 @@ var (
 // Maps and Slices are a special case, where only
 // map[string]interface{} and []interface{} are supported.
 // The interface for maps and slices may be more permissive in the future.
+//
+// Integers are encoded as Bolt's 64 bit INTEGER.  Every int is in range on
+// both 32 and 64 bit platforms.  A uint or uint64 greater than
+// math.MaxInt64, which is only possible for a uint on 64 bit platforms,
+// returns an error rather than wrapping around to a negative number.
+//
+// A nil value, including a nil map value or pointer, is always encoded as the
+// Nil marker, so passing a nil parameter is equivalent to passing null in
+// cypher.  Other pointers, such as a *bool for a nullable parameter, are
+// encoded as the value they point to.  A driver.Valuer, such as sql.NullBool,
+// is encoded as its Value, and a Marshaler as the value from MarshalBolt.
 type Encoder struct {
 	w         io.Writer
 	buf       *bytes.Buffer
 	chunkSize uint16
+	// FixedWidthInts encodes every integer as an INT_64, regardless of
+	// its magnitude.  Useful for byte-level comparisons of encoded output.
+	// Defaults to using the smallest encoding that fits the value.
+	FixedWidthInts bool
+	// StringifyStringers encodes values that implement error or
+	// fmt.Stringer, and aren't otherwise supported, as the string they
+	// return.  Defaults to returning an error for them like other
+	// unsupported types.
+	StringifyStringers bool
+	// EncodeStructs encodes structs, and pointers to them, that aren't
+	// otherwise supported as a map of their exported fields.  Fields are
+	// named by their bolt or json tag, and the "-" and omitempty tag
+	// options are honored.
+	// Defaults to returning an error for structs like other unsupported types.
+	EncodeStructs bool
+	// ErrorOnEmptyStruct returns an error for a struct encoded by
+	// EncodeStructs that has no fields to encode, such as one with only
+	// unexported fields, which is likely a bug.  Defaults to encoding it as
+	// an empty map.
+	ErrorOnEmptyStruct bool
+	// RejectNonFinite returns an error for NaN and infinite floats,
+	// wherever they are, including in lists and map values.  Defaults to
+	// encoding them as they are.
+	RejectNonFinite bool
+	// OnUnsupportedType is what to do with a value of a type that can't be
+	// encoded.  Defaults to UnsupportedTypeError.
+	OnUnsupportedType UnsupportedTypePolicy
 }
 
+// UnsupportedTypePolicy is what an Encoder does with values of types it
+// can't encode
+type UnsupportedTypePolicy int
+
+const (
+	// UnsupportedTypeError returns an error for the value
+	UnsupportedTypeError UnsupportedTypePolicy = iota
+	// UnsupportedTypeEncodeNil encodes the value as Nil in its place
+	UnsupportedTypeEncodeNil
+	// UnsupportedTypeSkip omits the key of the value from the map it is in.
+	// Skip only applies within maps: a value that isn't in a map, such as a
+	// slice element, is encoded as Nil instead since omitting it would
+	// shift the elements after it.
+	UnsupportedTypeSkip
+)
+
 // NewEncoder Creates a new Encoder object
 func NewEncoder(w io.Writer, chunkSize uint16) Encoder {
 	return Encoder{
@@ -163,10 +242,13 @@ func (e Encoder) Encode(iVal interface{}) error {
 	return e.flush()
 }
 
-// Encode encodes an object to the stream
+// encode encodes an object to the stream
 func (e Encoder) encode(iVal interface{}) error {
+	iVal, err := e.normalize(iVal)
+	if err != nil {
+		return err
+	}
 
-	var err error
 	switch val := iVal.(type) {
 	case nil:
 		err = e.encodeNil()
@@ -191,9 +273,6 @@ func (e Encoder) encode(iVal interface{}) error {
 	case uint32:
 		err = e.encodeInt(int64(val))
 	case uint64:
-		if val > math.MaxInt64 {
-			return errors.New("Integer too big: %d. Max integer supported: %d", val, math.MaxInt64)
-		}
 		err = e.encodeInt(int64(val))
 	case float32:
 		err = e.encodeFloat(float64(val))
@@ -207,23 +286,159 @@ func (e Encoder) encode(iVal interface{}) error {
 		err = e.encodeMap(val)
 	case structures.Structure:
 		err = e.encodeStructure(val)
+	case RawValue:
+		_, err = e.Write(val)
 	default:
-		// arbitrary slice types
-		if reflect.TypeOf(iVal).Kind() == reflect.Slice {
-			s := reflect.ValueOf(iVal)
-			newSlice := make([]interface{}, s.Len())
-			for i := 0; i < s.Len(); i++ {
-				newSlice[i] = s.Index(i).Interface()
-			}
-			return e.encodeSlice(newSlice)
-		}
-
 		return errors.New("Unrecognized type when encoding data for Bolt transport: %T %+v", val, val)
 	}
 
 	return err
 }
 
+// resolve gets what a value is encoded as.  Values of the types that are
+// written directly are returned as they are, and other values are
+// converted to one of them, such as the map from a Parameterizer.  ok is
+// false for a value of a type that can't be encoded.
+//
+// Both encode and appendValue write the resolved value, so this is the one
+// place that decides how each type is encoded.
+func (e Encoder) resolve(iVal interface{}) (val interface{}, ok bool, err error) {
+	switch v := iVal.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint8, uint16, uint32,
+		float32, float64, string, []interface{}, map[string]interface{}, RawValue:
+		return iVal, true, nil
+	case uint:
+		if uint64(v) > math.MaxInt64 {
+			return nil, true, errors.New("Integer too big: %d. Max integer supported: %d", v, int64(math.MaxInt64))
+		}
+		return iVal, true, nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return nil, true, errors.New("Integer too big: %d. Max integer supported: %d", v, int64(math.MaxInt64))
+		}
+		return iVal, true, nil
+	case structures.Structure:
+		// Structures with value receivers are also implemented by their
+		// pointers, which can't have their fields taken when nil
+		if isNilPointer(v) {
+			return nil, true, nil
+		}
+		return iVal, true, nil
+	case Marshaler:
+		if isNilPointer(v) {
+			return nil, true, nil
+		}
+		value, err := v.MarshalBolt()
+		if err != nil {
+			return nil, true, errors.Wrap(err, "An error occurred marshalling %T", v)
+		}
+		return e.resolve(value)
+	case Parameterizer:
+		if isNilPointer(v) {
+			return nil, true, nil
+		}
+		return v.Parameters(), true, nil
+	case driver.Valuer:
+		// Such as sql.NullBool and the other sql.Null types
+		if isNilPointer(v) {
+			return nil, true, nil
+		}
+		value, err := v.Value()
+		if err != nil {
+			return nil, true, errors.Wrap(err, "An error occurred getting value of %T", v)
+		}
+		return e.resolve(value)
+	}
+
+	v := reflect.ValueOf(iVal)
+
+	// arbitrary slice types
+	if v.Kind() == reflect.Slice {
+		newSlice := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			newSlice[i] = v.Index(i).Interface()
+		}
+		return newSlice, true, nil
+	}
+
+	if e.StringifyStringers {
+		switch s := iVal.(type) {
+		case error:
+			return s.Error(), true, nil
+		case fmt.Stringer:
+			return s.String(), true, nil
+		}
+	}
+
+	// other pointers are encoded as what they point to, or nil
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, true, nil
+		}
+		return e.resolve(v.Elem().Interface())
+	}
+
+	if e.EncodeStructs && v.Kind() == reflect.Struct {
+		m, err := structToMap(v, e.ErrorOnEmptyStruct)
+		return m, true, err
+	}
+
+	return nil, false, nil
+}
+
+// normalize resolves a value, applying OnUnsupportedType
+// to values of types that can't be encoded
+func (e Encoder) normalize(iVal interface{}) (interface{}, error) {
+	val, ok, err := e.resolve(iVal)
+	if err != nil || ok {
+		return val, err
+	}
+
+	if e.OnUnsupportedType != UnsupportedTypeError {
+		return nil, nil
+	}
+	return nil, errors.New("Unrecognized type when encoding data for Bolt transport: %T %+v", iVal, iVal)
+}
+
+// isSupported checks if the type of the value can be encoded.  Only the
+// value itself is checked, not the values it contains.  A value that fails
+// to resolve, such as a driver.Valuer returning an error, counts as
+// supported so that encoding it returns the error.
+func (e Encoder) isSupported(iVal interface{}) bool {
+	_, ok, err := e.resolve(iVal)
+	return ok || err != nil
+}
+
+// isNilPointer checks if a value is a nil pointer, which can't have
+// methods with value receivers called on it
+func isNilPointer(iVal interface{}) bool {
+	v := reflect.ValueOf(iVal)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// skipUnsupported drops the keys with unsupported values from the map when
+// OnUnsupportedType is UnsupportedTypeSkip.  The length of a map is written
+// first, so they have to be dropped up front.  The map is only copied when
+// there is something to drop.
+func (e Encoder) skipUnsupported(val map[string]interface{}) map[string]interface{} {
+	if e.OnUnsupportedType != UnsupportedTypeSkip {
+		return val
+	}
+
+	for _, v := range val {
+		if !e.isSupported(v) {
+			filtered := make(map[string]interface{}, len(val))
+			for k, v := range val {
+				if e.isSupported(v) {
+					filtered[k] = v
+				}
+			}
+			return filtered
+		}
+	}
+	return val
+}
+
 // encodeNil encodes a nil object to the stream
 func (e Encoder) encodeNil() error {
 	_, err := e.Write([]byte{NilMarker})
@@ -245,7 +460,7 @@ func (e Encoder) encodeBool(val bool) error {
 func (e Encoder) encodeInt(val int64) error {
 	var err error
 	switch {
-	case val >= math.MinInt64 && val < math.MinInt32:
+	case e.FixedWidthInts || (val >= math.MinInt64 && val < math.MinInt32):
 		// Write as INT_64
 		if _, err = e.Write([]byte{Int64Marker}); err != nil {
 			return err
@@ -301,6 +516,10 @@ func (e Encoder) encodeInt(val int64) error {
 
 // encodeFloat encodes a nil object to the stream
 func (e Encoder) encodeFloat(val float64) error {
+	if e.RejectNonFinite && (math.IsNaN(val) || math.IsInf(val, 0)) {
+		return errors.New("Non-finite float not allowed: %v", val)
+	}
+
 	if _, err := e.Write([]byte{FloatMarker}); err != nil {
 		return err
 	}
@@ -341,7 +560,7 @@ func (e Encoder) encodeString(val string) error {
 			return err
 		}
 		_, err = e.Write(bytes)
-	case length > math.MaxUint16 && length <= math.MaxUint32:
+	case length > math.MaxUint16 && int64(length) <= math.MaxUint32:
 		if _, err = e.Write([]byte{String32Marker}); err != nil {
 			return err
 		}
@@ -377,7 +596,7 @@ func (e Encoder) encodeSlice(val []interface{}) error {
 		if err := binary.Write(e, binary.BigEndian, int16(length)); err != nil {
 			return err
 		}
-	case length >= math.MaxUint16 && length <= math.MaxUint32:
+	case length >= math.MaxUint16 && int64(length) <= math.MaxUint32:
 		if _, err := e.Write([]byte{Slice32Marker}); err != nil {
 			return err
 		}
@@ -400,6 +619,8 @@ func (e Encoder) encodeSlice(val []interface{}) error {
 
 // encodeMap encodes a nil object to the stream
 func (e Encoder) encodeMap(val map[string]interface{}) error {
+	val = e.skipUnsupported(val)
+
 	length := len(val)
 	switch {
 	case length <= 15:
@@ -420,7 +641,7 @@ func (e Encoder) encodeMap(val map[string]interface{}) error {
 		if err := binary.Write(e, binary.BigEndian, int16(length)); err != nil {
 			return err
 		}
-	case length >= math.MaxUint16 && length <= math.MaxUint32:
+	case length >= math.MaxUint16 && int64(length) <= math.MaxUint32:
 		if _, err := e.Write([]byte{Map32Marker}); err != nil {
 			return err
 		}
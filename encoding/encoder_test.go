@@ -0,0 +1,440 @@
+package encoding
+
+import (
+	"bytes"
+	"database/sql"
+	stdErrors "errors"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
+)
+
+func TestEncoder_NilParameter(t *testing.T) {
+	encoded, err := Marshal(map[string]interface{}{"maybe": nil})
+	if err != nil {
+		t.Fatalf("An error occurred encoding nil parameter: %s", err)
+	}
+
+	expected := []byte{0x00, 0x08, TinyMapMarker + 1, TinyStringMarker + 5, 'm', 'a', 'y', 'b', 'e', NilMarker, 0x00, 0x00}
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("Expected nil parameter to encode as nil marker. Expected %x. Got: %x", expected, encoded)
+	}
+
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding nil parameter: %s", err)
+	}
+
+	params := decoded.(map[string]interface{})
+	maybe, ok := params["maybe"]
+	if !ok {
+		t.Fatalf("Expected nil parameter to be present after decoding. Got: %#v", params)
+	}
+	if maybe != nil {
+		t.Fatalf("Expected nil parameter to decode to nil. Got: %#v", maybe)
+	}
+}
+
+func TestEncoder_RawValue(t *testing.T) {
+	// INT_16 encoding of 256
+	raw := RawValue{Int16Marker, 0x01, 0x00}
+
+	encoded, err := Marshal(map[string]interface{}{"a": raw})
+	if err != nil {
+		t.Fatalf("An error occurred encoding raw value: %s", err)
+	}
+
+	expected, err := Marshal(map[string]interface{}{"a": 256})
+	if err != nil {
+		t.Fatalf("An error occurred encoding int value: %s", err)
+	}
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("Expected raw value to be written verbatim. Expected %x. Got: %x", expected, encoded)
+	}
+
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding raw value: %s", err)
+	}
+	if decoded.(map[string]interface{})["a"] != int64(256) {
+		t.Fatalf("Expected raw value to round trip to 256. Got: %#v", decoded)
+	}
+}
+
+func TestEncoder_MixedNumericMap(t *testing.T) {
+	encoded, err := Marshal(map[string]interface{}{"i": int64(3), "f": float64(3.0)})
+	if err != nil {
+		t.Fatalf("An error occurred encoding mixed numeric map: %s", err)
+	}
+
+	// The int should be a TINY_INT, and the float should be a FLOAT_64,
+	// regardless of the float having no fractional part
+	if !bytes.Contains(encoded, []byte{TinyStringMarker + 1, 'i', 0x03}) {
+		t.Fatalf("Expected int to be encoded as TINY_INT. Got: %x", encoded)
+	}
+	if !bytes.Contains(encoded, []byte{TinyStringMarker + 1, 'f', FloatMarker, 0x40, 0x08, 0, 0, 0, 0, 0, 0}) {
+		t.Fatalf("Expected float to be encoded as FLOAT_64. Got: %x", encoded)
+	}
+
+	decoded, err := Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding mixed numeric map: %s", err)
+	}
+
+	params := decoded.(map[string]interface{})
+	if val, ok := params["i"].(int64); !ok || val != 3 {
+		t.Fatalf("Expected int to decode as int64. Got: %#v", params["i"])
+	}
+	if val, ok := params["f"].(float64); !ok || val != 3.0 {
+		t.Fatalf("Expected float to decode as float64. Got: %#v", params["f"])
+	}
+}
+
+func TestEncoder_StructurePointer(t *testing.T) {
+	node := graph.Node{NodeIdentity: 1, Labels: []string{"Foo"}, Properties: map[string]interface{}{}}
+
+	expected, err := Marshal(node)
+	if err != nil {
+		t.Fatalf("An error occurred encoding node: %s", err)
+	}
+
+	encoded, err := Marshal(&node)
+	if err != nil {
+		t.Fatalf("An error occurred encoding node pointer: %s", err)
+	}
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("Expected node pointer to encode as a structure. Expected %x. Got: %x", expected, encoded)
+	}
+
+	var nilNode *graph.Node
+	encoded, err = Marshal(nilNode)
+	if err != nil {
+		t.Fatalf("An error occurred encoding nil node pointer: %s", err)
+	}
+	if !bytes.Equal(encoded, []byte{0x00, 0x01, NilMarker, 0x00, 0x00}) {
+		t.Fatalf("Expected nil node pointer to encode as nil. Got: %x", encoded)
+	}
+}
+
+func TestEncoder_FixedWidthInts(t *testing.T) {
+	for _, val := range []interface{}{0, int8(1), int16(-5), 300, int64(70000)} {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, math.MaxUint16)
+		enc.FixedWidthInts = true
+		if err := enc.Encode(val); err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", val, err)
+		}
+
+		// Chunk header, INT_64 marker, 8 bytes of int, end message
+		encoded := buf.Bytes()
+		if len(encoded) != 13 || encoded[2] != Int64Marker {
+			t.Fatalf("Expected %#v to be encoded as INT_64. Got: %#v", val, encoded)
+		}
+	}
+
+	// Variable width is the default
+	encoded, err := Marshal(1)
+	if err != nil {
+		t.Fatalf("An error occurred encoding int: %s", err)
+	}
+	if !bytes.Equal(encoded, []byte{0x00, 0x01, 0x01, 0x00, 0x00}) {
+		t.Fatalf("Expected small int to be encoded as TINY_INT by default. Got: %#v", encoded)
+	}
+}
+
+func TestEncoder_PlatformInts(t *testing.T) {
+	// Every int fits in an INT_64, on any platform
+	for _, val := range []interface{}{int(math.MaxInt32), int(math.MinInt32), maxInt, minInt} {
+		if _, err := Marshal(val); err != nil {
+			t.Fatalf("An error occurred encoding int %d: %s", val, err)
+		}
+	}
+
+	// A uint only overflows an INT_64 on 64 bit platforms
+	_, err := Marshal(maxUint)
+	if strconv.IntSize == 64 && err == nil {
+		t.Fatalf("Expected error encoding uint %d on a 64 bit platform", maxUint)
+	} else if strconv.IntSize == 32 && err != nil {
+		t.Fatalf("An error occurred encoding uint %d on a 32 bit platform: %s", maxUint, err)
+	}
+
+	encoded, err := Marshal(uint(math.MaxUint32))
+	if err != nil {
+		t.Fatalf("An error occurred encoding uint %d: %s", uint(math.MaxUint32), err)
+	}
+	expected, _ := Marshal(int64(math.MaxUint32))
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("Expected uint to be encoded as INT_64 %d. Got: %#v", int64(math.MaxUint32), encoded)
+	}
+
+	if _, err := Marshal(uint64(math.MaxUint64)); err == nil {
+		t.Fatal("Expected error encoding uint64 greater than max INT_64")
+	}
+}
+
+// The bounds of int and uint for the platform the tests are built for
+const (
+	maxUint = ^uint(0)
+	maxInt  = int(maxUint >> 1)
+	minInt  = -maxInt - 1
+)
+
+func TestEncoder_BoolPointer(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		val      interface{}
+		expected interface{}
+	}{
+		{(*bool)(nil), nil},
+		{&yes, true},
+		{&no, false},
+		{sql.NullBool{}, nil},
+		{sql.NullBool{Bool: true, Valid: true}, true},
+		{sql.NullBool{Bool: false, Valid: true}, false},
+	}
+
+	for _, test := range tests {
+		encoded, err := Marshal(map[string]interface{}{"flag": test.val})
+		if err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", test.val, err)
+		}
+		expected, err := Marshal(map[string]interface{}{"flag": test.expected})
+		if err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", test.expected, err)
+		}
+		if !bytes.Equal(encoded, expected) {
+			t.Fatalf("Expected %#v to be encoded as %#v. Got: %#v", test.val, test.expected, encoded)
+		}
+	}
+}
+
+func TestEncoder_NilValuerPointer(t *testing.T) {
+	for _, val := range []interface{}{(*sql.NullString)(nil), map[string]interface{}{"name": (*sql.NullString)(nil)}} {
+		encoded, err := Marshal(val)
+		if err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", val, err)
+		}
+		appended, err := AppendEncode(nil, val)
+		if err != nil {
+			t.Fatalf("An error occurred append encoding %#v: %s", val, err)
+		}
+		if !bytes.Equal(encoded, appended) {
+			t.Fatalf("Append encoding of %#v differs from Encode. Expected %#v. Got: %#v", val, encoded, appended)
+		}
+
+		decoded, err := Unmarshal(encoded)
+		if err != nil {
+			t.Fatalf("An error occurred decoding %#v: %s", val, err)
+		}
+		if m, ok := decoded.(map[string]interface{}); ok {
+			decoded = m["name"]
+		}
+		if decoded != nil {
+			t.Fatalf("Expected nil *sql.NullString to be encoded as nil. Got: %#v", decoded)
+		}
+	}
+}
+
+type testStringer struct{ name string }
+
+func (s testStringer) String() string { return "stringer " + s.name }
+
+func TestEncoder_StringifyStringers(t *testing.T) {
+	tests := []struct {
+		val      interface{}
+		expected string
+	}{
+		{testStringer{name: "foo"}, "stringer foo"},
+		{stdErrors.New("an error"), "an error"},
+	}
+
+	for _, test := range tests {
+		if _, err := Marshal(test.val); err == nil {
+			t.Fatalf("Expected error encoding %#v without StringifyStringers", test.val)
+		}
+
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, math.MaxUint16)
+		enc.StringifyStringers = true
+		if err := enc.Encode(test.val); err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", test.val, err)
+		}
+
+		expected, err := Marshal(test.expected)
+		if err != nil {
+			t.Fatalf("An error occurred encoding string: %s", err)
+		}
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Fatalf("Expected %#v to be encoded as %q. Got: %#v", test.val, test.expected, buf.Bytes())
+		}
+	}
+}
+
+func TestEncoder_UnexportedFieldStruct(t *testing.T) {
+	// Plain structs aren't encoded by reflection by default
+	type unexported struct {
+		name string
+		age  int
+	}
+
+	if _, err := Marshal(unexported{name: "foo", age: 1}); err == nil {
+		t.Fatal("Expected error encoding struct with only unexported fields")
+	}
+	if _, err := Marshal(map[string]interface{}{"param": unexported{}}); err == nil {
+		t.Fatal("Expected error encoding parameter struct with only unexported fields")
+	}
+
+	// With EncodeStructs, it's an empty map unless ErrorOnEmptyStruct is set
+	buf := &bytes.Buffer{}
+	enc := NewEncoder(buf, math.MaxUint16)
+	enc.EncodeStructs = true
+	if err := enc.Encode(unexported{name: "foo", age: 1}); err != nil {
+		t.Fatalf("An error occurred encoding struct with only unexported fields: %s", err)
+	}
+	expected, err := Marshal(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("An error occurred encoding empty map: %s", err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("Expected struct with only unexported fields to be an empty map. Got: %#v", buf.Bytes())
+	}
+
+	enc = NewEncoder(&bytes.Buffer{}, math.MaxUint16)
+	enc.EncodeStructs = true
+	enc.ErrorOnEmptyStruct = true
+	if err := enc.Encode(unexported{name: "foo", age: 1}); err == nil {
+		t.Fatal("Expected error encoding struct with only unexported fields with ErrorOnEmptyStruct")
+	}
+	if err := enc.Encode(map[string]interface{}{"param": unexported{}}); err == nil {
+		t.Fatal("Expected error encoding parameter struct with only unexported fields with ErrorOnEmptyStruct")
+	}
+}
+
+func TestEncoder_EncodeStructs(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Base struct {
+		ID int64 `bolt:"id"`
+	}
+	type person struct {
+		Base
+		Name     string   `bolt:"name" json:"full_name"`
+		Nickname string   `json:"nickname,omitempty"`
+		Tags     []string `bolt:"tags,omitempty"`
+		Secret   string   `bolt:"-"`
+		Age      int
+		Address  *Address `json:"address"`
+		Manager  *Address `json:"manager"`
+		internal string
+	}
+
+	p := person{
+		Base:     Base{ID: 7},
+		Name:     "alice",
+		Secret:   "shh",
+		Age:      30,
+		Address:  &Address{City: "London"},
+		internal: "x",
+	}
+
+	if _, err := Marshal(p); err == nil {
+		t.Fatal("Expected error encoding struct without EncodeStructs")
+	}
+
+	for _, val := range []interface{}{p, &p} {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, math.MaxUint16)
+		enc.EncodeStructs = true
+		if err := enc.Encode(val); err != nil {
+			t.Fatalf("An error occurred encoding struct: %s", err)
+		}
+
+		decoded, err := Unmarshal(buf.Bytes())
+		if err != nil {
+			t.Fatalf("An error occurred decoding struct: %s", err)
+		}
+
+		expected := map[string]interface{}{
+			"id":      int64(7),
+			"name":    "alice",
+			"Age":     int64(30),
+			"address": map[string]interface{}{"city": "London"},
+			"manager": nil,
+		}
+		if !reflect.DeepEqual(decoded, expected) {
+			t.Fatalf("Unexpected decoded struct. Expected: %#v. Got: %#v", expected, decoded)
+		}
+	}
+}
+
+func TestEncoder_OnUnsupportedType(t *testing.T) {
+	tests := []struct {
+		policy      UnsupportedTypePolicy
+		val         interface{}
+		expected    interface{}
+		expectedErr bool
+	}{
+		{UnsupportedTypeError, map[string]interface{}{"a": int64(1), "b": make(chan int)}, nil, true},
+		{UnsupportedTypeError, []interface{}{1, make(chan int)}, nil, true},
+		{UnsupportedTypeEncodeNil, map[string]interface{}{"a": int64(1), "b": make(chan int)}, map[string]interface{}{"a": int64(1), "b": nil}, false},
+		{UnsupportedTypeEncodeNil, []interface{}{1, make(chan int)}, []interface{}{int64(1), nil}, false},
+		{UnsupportedTypeSkip, map[string]interface{}{"a": int64(1), "b": make(chan int)}, map[string]interface{}{"a": int64(1)}, false},
+		{UnsupportedTypeSkip, []interface{}{1, make(chan int)}, []interface{}{int64(1), nil}, false},
+		{UnsupportedTypeSkip, map[string]interface{}{"a": []interface{}{make(chan int)}}, map[string]interface{}{"a": []interface{}{nil}}, false},
+	}
+
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		enc := NewEncoder(buf, math.MaxUint16)
+		enc.OnUnsupportedType = test.policy
+		err := enc.Encode(test.val)
+		if test.expectedErr {
+			if err == nil {
+				t.Fatalf("Expected error encoding %#v with policy %d", test.val, test.policy)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("An error occurred encoding %#v with policy %d: %s", test.val, test.policy, err)
+		}
+
+		// Map ordering isn't stable, so compare what it decodes to
+		decoded, err := NewDecoder(buf).Decode()
+		if err != nil {
+			t.Fatalf("An error occurred decoding %#v: %s", buf.Bytes(), err)
+		}
+		if !reflect.DeepEqual(decoded, test.expected) {
+			t.Fatalf("Unexpected encoding of %#v with policy %d. Expected %#v. Got: %#v", test.val, test.policy, test.expected, decoded)
+		}
+	}
+}
+
+func TestEncoder_RejectNonFinite(t *testing.T) {
+	vals := []interface{}{
+		[]interface{}{1.5, math.NaN()},
+		map[string]interface{}{"a": map[string]interface{}{"b": math.Inf(1)}},
+		[]interface{}{map[string]interface{}{"a": float32(math.Inf(-1))}},
+	}
+
+	for _, val := range vals {
+		if _, err := Marshal(val); err != nil {
+			t.Fatalf("An error occurred encoding %#v without RejectNonFinite: %s", val, err)
+		}
+
+		enc := NewEncoder(&bytes.Buffer{}, math.MaxUint16)
+		enc.RejectNonFinite = true
+		if err := enc.Encode(val); err == nil {
+			t.Fatalf("Expected error encoding %#v with RejectNonFinite", val)
+		}
+	}
+
+	enc := NewEncoder(&bytes.Buffer{}, math.MaxUint16)
+	enc.RejectNonFinite = true
+	if err := enc.Encode([]interface{}{1.5, map[string]interface{}{"a": -2.5}}); err != nil {
+		t.Fatalf("An error occurred encoding finite floats with RejectNonFinite: %s", err)
+	}
+}
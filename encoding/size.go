@@ -0,0 +1,45 @@
+package encoding
+
+import (
+	"io"
+)
+
+// CountingWriter counts the bytes written to it, passing them on to W,
+// or discarding them if W is nil.  Use it with NewEncoder to measure the
+// size of a message on the wire, including its chunk headers and end
+// marker, without sending it:
+//
+//	cw := &CountingWriter{}
+//	err := NewEncoder(cw, math.MaxUint16).Encode(msg)
+//	size := cw.Count
+type CountingWriter struct {
+	W     io.Writer
+	Count int
+}
+
+// Write counts the bytes written
+func (w *CountingWriter) Write(p []byte) (int, error) {
+	if w.W == nil {
+		w.Count += len(p)
+		return len(p), nil
+	}
+
+	n, err := w.W.Write(p)
+	w.Count += n
+	return n, err
+}
+
+// EncodedSize gets the number of bytes v is encoded as, without the
+// chunk headers and end marker a message is framed with.  Use
+// Encoder.EncodedSize to get the size with the options of an Encoder.
+func EncodedSize(v interface{}) (int, error) {
+	return Encoder{}.EncodedSize(v)
+}
+
+// EncodedSize gets the number of bytes v is encoded as with the options of
+// the Encoder, without the chunk headers and end marker a message is
+// framed with
+func (e Encoder) EncodedSize(v interface{}) (int, error) {
+	b, err := e.appendValue(nil, v)
+	return len(b), err
+}
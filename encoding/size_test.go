@@ -0,0 +1,62 @@
+package encoding
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEncodedSize(t *testing.T) {
+	tests := []struct {
+		val  interface{}
+		size int
+	}{
+		{nil, 1},
+		{true, 1},
+		{1, 1},
+		{-100, 2},
+		{1000, 3},
+		{int32(math.MaxInt32), 5},
+		{int64(math.MaxInt64), 9},
+		{1.5, 9},
+		{"abc", 4},
+		{strings.Repeat("a", 20), 22},
+		{[]interface{}{1, 2}, 3},
+		{map[string]interface{}{"a": 1}, 4},
+	}
+
+	for _, test := range tests {
+		size, err := EncodedSize(test.val)
+		if err != nil {
+			t.Fatalf("An error occurred getting size of %#v: %s", test.val, err)
+		}
+		if size != test.size {
+			t.Fatalf("Unexpected size of %#v. Expected: %d. Got: %d", test.val, test.size, size)
+		}
+	}
+
+	if _, err := EncodedSize(make(chan int)); err == nil {
+		t.Fatal("Expected error getting size of unsupported type")
+	}
+}
+
+func TestCountingWriter(t *testing.T) {
+	// A tiny int, plus the 2 byte chunk header and 2 byte end marker
+	cw := &CountingWriter{}
+	if err := NewEncoder(cw, math.MaxUint16).Encode(1); err != nil {
+		t.Fatalf("An error occurred encoding: %s", err)
+	}
+	if cw.Count != 5 {
+		t.Fatalf("Expected 5 bytes written. Got: %d", cw.Count)
+	}
+
+	buf := &bytes.Buffer{}
+	cw = &CountingWriter{W: buf}
+	if err := NewEncoder(cw, math.MaxUint16).Encode("abc"); err != nil {
+		t.Fatalf("An error occurred encoding: %s", err)
+	}
+	if cw.Count != buf.Len() || cw.Count != 8 {
+		t.Fatalf("Expected 8 bytes written through. Got: %d counted, %d written", cw.Count, buf.Len())
+	}
+}
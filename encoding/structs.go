@@ -0,0 +1,91 @@
+package encoding
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+)
+
+// structToMap gets a map of the exported fields of a struct, named by their
+// bolt tag, or json tag if there's no bolt tag, or else the field name.
+// Fields tagged "-" are skipped, as are empty fields tagged omitempty.
+// The fields of embedded structs without a tag name are included as if
+// they were fields of the outer struct.  If errorOnEmpty is set, a struct
+// that has no fields to encode is an error rather than an empty map.
+func structToMap(v reflect.Value, errorOnEmpty bool) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	addStructFields(m, v)
+	if len(m) == 0 && errorOnEmpty {
+		return nil, errors.New("Struct has no fields to encode: %s", v.Type())
+	}
+	return m, nil
+}
+
+func addStructFields(m map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue // unexported
+		}
+
+		name, omitEmpty, skip := fieldTag(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructFields(m, v.Field(i))
+			continue
+		} else if field.PkgPath != "" {
+			continue // unexported embedded non-struct
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if omitEmpty && isEmptyValue(v.Field(i)) {
+			continue
+		}
+		m[name] = v.Field(i).Interface()
+	}
+}
+
+// fieldTag gets the name and options from the bolt or json tag of a field
+func fieldTag(field reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag, ok := field.Tag.Lookup("bolt")
+	if !ok {
+		tag = field.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, false
+}
+
+// isEmptyValue checks if a value is empty for omitempty, as encoding/json does
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
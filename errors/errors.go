@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime/debug"
 	"strings"
+	"time"
 )
 
 // Error is the base error type adds stack trace and wrapping errors
@@ -39,6 +40,22 @@ func Wrap(err error, msg string, args ...interface{}) *Error {
 	}
 }
 
+// Unwrap gets the error wrapped by this error, if any
+func (e *Error) Unwrap() error {
+	return e.wrapped
+}
+
+// Cause gets the innermost error wrapped by the given error
+func Cause(err error) error {
+	for {
+		e, ok := err.(*Error)
+		if !ok || e.wrapped == nil {
+			return err
+		}
+		err = e.wrapped
+	}
+}
+
 // Error gets the error output
 func (e *Error) Error() string {
 	return e.error(0)
@@ -60,3 +77,181 @@ func (e *Error) error(level int) string {
 
 	return msg
 }
+
+// HandshakeTimeoutError is returned when the server doesn't complete the
+// handshake and INIT within the handshake timeout.  This is distinct from
+// timeouts that occur while running queries.
+type HandshakeTimeoutError struct {
+	Timeout time.Duration
+	wrapped error
+}
+
+// NewHandshakeTimeoutError makes a new handshake timeout error
+func NewHandshakeTimeoutError(timeout time.Duration, err error) *HandshakeTimeoutError {
+	return &HandshakeTimeoutError{
+		Timeout: timeout,
+		wrapped: err,
+	}
+}
+
+// Error gets the error output
+func (e *HandshakeTimeoutError) Error() string {
+	msg := fmt.Sprintf("Handshake with server did not complete within %s", e.Timeout)
+	if e.wrapped != nil {
+		msg += fmt.Sprintf("\n%s", e.wrapped.Error())
+	}
+	return msg
+}
+
+// Unwrap gets the error wrapped by this error, if any
+func (e *HandshakeTimeoutError) Unwrap() error {
+	return e.wrapped
+}
+
+// IsHandshakeTimeout checks if the given error was caused by a handshake timeout
+func IsHandshakeTimeout(err error) bool {
+	_, ok := Cause(err).(*HandshakeTimeoutError)
+	return ok
+}
+
+// ProtocolVersionError is returned when the server doesn't support a
+// protocol version the driver can use.  Retrying won't help, since the
+// server negotiates the same version every time.
+type ProtocolVersionError struct {
+	// Version is the version the server negotiated, or 0 if none
+	Version uint32
+	// MinVersion is the lowest version that was accepted
+	MinVersion uint32
+}
+
+// NewProtocolVersionError makes a new protocol version error for the version negotiated
+func NewProtocolVersionError(version uint32, minVersion uint32) *ProtocolVersionError {
+	return &ProtocolVersionError{
+		Version:    version,
+		MinVersion: minVersion,
+	}
+}
+
+// Error gets the error output
+func (e *ProtocolVersionError) Error() string {
+	if e.Version == 0 {
+		return "Server responded with no supported version"
+	}
+	return fmt.Sprintf("Server negotiated protocol version %d, but version %d or higher is required", e.Version, e.MinVersion)
+}
+
+// IsProtocolVersion checks if the given error was caused by the server not
+// supporting a usable protocol version
+func IsProtocolVersion(err error) bool {
+	_, ok := Cause(err).(*ProtocolVersionError)
+	return ok
+}
+
+const (
+	// UnauthorizedCode is the failure code sent by the server when the credentials are incorrect
+	UnauthorizedCode = "Neo.ClientError.Security.Unauthorized"
+	// CredentialsExpiredCode is the failure code sent by the server when the password must be changed
+	CredentialsExpiredCode = "Neo.ClientError.Security.CredentialsExpired"
+	// AuthenticationRateLimitCode is the failure code sent by the server when the account is locked
+	// out after too many failed authentication attempts
+	AuthenticationRateLimitCode = "Neo.ClientError.Security.AuthenticationRateLimit"
+	// TokenExpiredCode is the failure code sent by the server when an auth token has expired
+	TokenExpiredCode = "Neo.ClientError.Security.TokenExpired"
+)
+
+// AuthError is returned when the server rejects the credentials given when
+// initializing a connection.  Use errors.Cause to get it from a wrapped error.
+type AuthError struct {
+	Code    string
+	Message string
+}
+
+// NewAuthError makes a new auth error from the code and message of a failure
+func NewAuthError(code string, message string) *AuthError {
+	return &AuthError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Error gets the error output
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("Authentication failed (%s): %s", e.Code, e.Message)
+}
+
+// IsWrongCredentials checks if the user or password given was incorrect
+func (e *AuthError) IsWrongCredentials() bool {
+	return e.Code == UnauthorizedCode
+}
+
+// IsPasswordChangeRequired checks if the password has expired and must be changed
+func (e *AuthError) IsPasswordChangeRequired() bool {
+	return e.Code == CredentialsExpiredCode
+}
+
+// IsAccountLocked checks if the account was locked out after too many failed attempts
+func (e *AuthError) IsAccountLocked() bool {
+	return e.Code == AuthenticationRateLimitCode
+}
+
+// IsTokenExpired checks if the auth token has expired and a new one must be given
+func (e *AuthError) IsTokenExpired() bool {
+	return e.Code == TokenExpiredCode
+}
+
+// TransientErrorPrefix is the prefix of the failure codes for errors
+// that may succeed if the transaction is retried, such as deadlocks
+const TransientErrorPrefix = "Neo.TransientError."
+
+// ServerError is returned when the server responds to a message with a
+// failure.  Use errors.Cause to get it from a wrapped error.
+type ServerError struct {
+	Code    string
+	Message string
+}
+
+// NewServerError makes a new server error from the code and message of a failure
+func NewServerError(code string, message string) *ServerError {
+	return &ServerError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Error gets the error output
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("Server failure (%s): %s", e.Code, e.Message)
+}
+
+// IsTransient checks if the failure may succeed if the transaction is retried
+func (e *ServerError) IsTransient() bool {
+	return strings.HasPrefix(e.Code, TransientErrorPrefix)
+}
+
+// IsTransient checks if the given error was caused by a transient server failure
+func IsTransient(err error) bool {
+	serverErr, ok := Cause(err).(*ServerError)
+	return ok && serverErr.IsTransient()
+}
+
+// RowLimitError is returned when a query returns more rows than its limit
+type RowLimitError struct {
+	MaxRows int
+}
+
+// NewRowLimitError makes a new row limit error for the limit exceeded
+func NewRowLimitError(maxRows int) *RowLimitError {
+	return &RowLimitError{MaxRows: maxRows}
+}
+
+// Error gets the error output
+func (e *RowLimitError) Error() string {
+	return fmt.Sprintf("Row limit exceeded: query returned more than %d rows", e.MaxRows)
+}
+
+// IsRowLimitExceeded checks if the given error was caused by a query
+// returning more rows than its limit
+func IsRowLimitExceeded(err error) bool {
+	_, ok := Cause(err).(*RowLimitError)
+	return ok
+}
@@ -0,0 +1,74 @@
+package golangNeo4jBoltDriver
+
+import (
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
+)
+
+// ToJSON converts a decoded value, such as a row from NextNeo, into values that
+// encode cleanly with json.Marshal.  Graph structures are converted to maps:
+//
+//	Node:                {"id", "labels", "props"}
+//	Relationship:        {"id", "type", "start", "end", "props"}
+//	UnboundRelationship: {"id", "type", "props"}
+//	Path:                {"nodes", "relationships", "sequence"}
+//
+// Any other structure is converted to {"signature", "fields"}.
+// Lists and maps are converted recursively.
+func ToJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case graph.Node:
+		return map[string]interface{}{
+			"id":     v.NodeIdentity,
+			"labels": v.Labels,
+			"props":  ToJSON(v.Properties),
+		}
+	case graph.Relationship:
+		return map[string]interface{}{
+			"id":    v.RelIdentity,
+			"type":  v.Type,
+			"start": v.StartNodeIdentity,
+			"end":   v.EndNodeIdentity,
+			"props": ToJSON(v.Properties),
+		}
+	case graph.UnboundRelationship:
+		return map[string]interface{}{
+			"id":    v.RelIdentity,
+			"type":  v.Type,
+			"props": ToJSON(v.Properties),
+		}
+	case graph.Path:
+		nodes := make([]interface{}, len(v.Nodes))
+		for i, node := range v.Nodes {
+			nodes[i] = ToJSON(node)
+		}
+		rels := make([]interface{}, len(v.Relationships))
+		for i, rel := range v.Relationships {
+			rels[i] = ToJSON(rel)
+		}
+		return map[string]interface{}{
+			"nodes":         nodes,
+			"relationships": rels,
+			"sequence":      v.Sequence,
+		}
+	case structures.Structure:
+		return map[string]interface{}{
+			"signature": v.Signature(),
+			"fields":    ToJSON(v.AllFields()),
+		}
+	case map[string]interface{}:
+		output := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			output[key] = ToJSON(val)
+		}
+		return output
+	case []interface{}:
+		output := make([]interface{}, len(v))
+		for i, val := range v {
+			output[i] = ToJSON(val)
+		}
+		return output
+	default:
+		return v
+	}
+}
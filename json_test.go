@@ -0,0 +1,31 @@
+package golangNeo4jBoltDriver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
+)
+
+func TestToJSON(t *testing.T) {
+	row := []interface{}{
+		graph.Node{NodeIdentity: 1, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "a"}},
+		graph.Relationship{RelIdentity: 2, StartNodeIdentity: 1, EndNodeIdentity: 3, Type: "KNOWS", Properties: map[string]interface{}{"since": int64(2001)}},
+		map[string]interface{}{"other": structures.GenericStructure{StructSignature: 0x7A, Fields: []interface{}{int64(1)}}},
+	}
+
+	output, err := json.Marshal(ToJSON(row))
+	if err != nil {
+		t.Fatalf("An error occurred marshaling row to JSON: %s", err)
+	}
+
+	expected := `[` +
+		`{"id":1,"labels":["Person"],"props":{"name":"a"}},` +
+		`{"end":3,"id":2,"props":{"since":2001},"start":1,"type":"KNOWS"},` +
+		`{"other":{"fields":[1],"signature":122}}` +
+		`]`
+	if string(output) != expected {
+		t.Fatalf("Unexpected JSON. Expected %s. Got: %s", expected, output)
+	}
+}
@@ -0,0 +1,66 @@
+package golangNeo4jBoltDriver
+
+import (
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+)
+
+// Money is an exact amount of money, stored as an integer number of minor
+// units, such as cents, since bolt has no decimal type.
+//
+// By default it is encoded as a map of amount, currency and scale.  Set
+// EncodeAsInt to encode only the amount, in which case the currency and
+// scale have to be known when reading it back.  Money can be used as a
+// destination with Scanner to read either encoding back.
+type Money struct {
+	// Amount is the amount in minor units, so 12345 for $123.45
+	Amount int64
+	// Currency is the currency code, such as USD
+	Currency string
+	// Scale is the number of digits of the minor units, so 2 for cents
+	Scale int
+	// EncodeAsInt encodes only the amount, as a plain integer
+	EncodeAsInt bool
+}
+
+// MarshalBolt gets the value to encode for the money.  It implements
+// encoding.Marshaler rather than driver.Valuer, since the map it's
+// encoded as by default isn't a driver.Value.
+func (m Money) MarshalBolt() (interface{}, error) {
+	if m.EncodeAsInt {
+		return m.Amount, nil
+	}
+	return map[string]interface{}{
+		"amount":   m.Amount,
+		"currency": m.Currency,
+		"scale":    int64(m.Scale),
+	}, nil
+}
+
+// Scan reads money from either of its encodings.  Scanning a plain integer
+// only sets the amount, keeping the currency and scale already set.
+// Scanning nil sets the money to its zero value.
+func (m *Money) Scan(src interface{}) error {
+	switch val := src.(type) {
+	case nil:
+		*m = Money{}
+	case int64:
+		m.Amount = val
+	case map[string]interface{}:
+		amount, ok := val["amount"].(int64)
+		if !ok {
+			return errors.New("Expected money amount to be an integer. Got: %#v", val["amount"])
+		}
+		currency, ok := val["currency"].(string)
+		if !ok {
+			return errors.New("Expected money currency to be a string. Got: %#v", val["currency"])
+		}
+		scale, ok := val["scale"].(int64)
+		if !ok {
+			return errors.New("Expected money scale to be an integer. Got: %#v", val["scale"])
+		}
+		m.Amount, m.Currency, m.Scale = amount, currency, int(scale)
+	default:
+		return errors.New("Cannot scan %T into money", src)
+	}
+	return nil
+}
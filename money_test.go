@@ -0,0 +1,82 @@
+package golangNeo4jBoltDriver
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/encoding"
+)
+
+func TestMoney_RoundTrip(t *testing.T) {
+	tests := []struct {
+		money    Money
+		expected Money
+	}{
+		{Money{Amount: 12345, Currency: "USD", Scale: 2}, Money{Amount: 12345, Currency: "USD", Scale: 2}},
+		// Only the amount is encoded, so the rest is kept from the destination
+		{Money{Amount: 12345, Currency: "USD", Scale: 2, EncodeAsInt: true}, Money{Amount: 12345, Currency: "EUR", Scale: 2}},
+	}
+
+	for _, test := range tests {
+		encoded, err := encoding.Marshal(test.money)
+		if err != nil {
+			t.Fatalf("An error occurred encoding %#v: %s", test.money, err)
+		}
+		decoded, err := encoding.Unmarshal(encoded)
+		if err != nil {
+			t.Fatalf("An error occurred decoding %#v: %s", test.money, err)
+		}
+		if test.money.EncodeAsInt && decoded != int64(12345) {
+			t.Fatalf("Expected money to be encoded as an integer. Got: %#v", decoded)
+		}
+
+		money := Money{Currency: "EUR", Scale: 2}
+		if err := (Scanner{}).Scan([]interface{}{decoded}, &money); err != nil {
+			t.Fatalf("An error occurred scanning %#v: %s", decoded, err)
+		}
+		if money != test.expected {
+			t.Fatalf("Unexpected scanned money. Expected %#v. Got: %#v", test.expected, money)
+		}
+	}
+}
+
+func TestMoney_ScanInvalid(t *testing.T) {
+	var money Money
+	if err := (Scanner{}).Scan([]interface{}{1.5}, &money); err == nil {
+		t.Fatal("Expected error scanning a float into money")
+	}
+	if err := (Scanner{}).Scan([]interface{}{map[string]interface{}{"amount": "12345"}}, &money); err == nil {
+		t.Fatal("Expected error scanning a map with a string amount into money")
+	}
+
+	money = Money{Amount: 1, Currency: "USD", Scale: 2}
+	if err := (Scanner{}).Scan([]interface{}{nil}, &money); err != nil {
+		t.Fatalf("An error occurred scanning nil into money: %s", err)
+	}
+	if money != (Money{}) {
+		t.Fatalf("Expected nil to scan as zero money. Got: %#v", money)
+	}
+}
+
+func TestMoney_Marshaler(t *testing.T) {
+	// The map isn't a valid driver.Value, so money must not claim to be one
+	if _, ok := interface{}(Money{}).(driver.Valuer); ok {
+		t.Fatal("Expected money not to implement driver.Valuer")
+	}
+
+	params := map[string]interface{}{"price": Money{Amount: 12345, Currency: "USD", Scale: 2}}
+	encoded, err := encoding.AppendEncode(nil, params)
+	if err != nil {
+		t.Fatalf("An error occurred encoding money param: %s", err)
+	}
+	decoded, err := encoding.Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding money param: %s", err)
+	}
+
+	expected := map[string]interface{}{"price": map[string]interface{}{"amount": int64(12345), "currency": "USD", "scale": int64(2)}}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("Unexpected encoded money param. Expected %#v. Got: %#v", expected, decoded)
+	}
+}
@@ -0,0 +1,200 @@
+package golangNeo4jBoltDriver
+
+import (
+	"io/ioutil"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/encoding"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+)
+
+// ParamRedactor is applied to each parameter before it's logged or included in an error, so that sensitive
+// values aren't exposed.  It's given the key and value and returns the value to log.
+// This can be manually overridden.  Defaults to DefaultParamRedactor.
+var ParamRedactor = DefaultParamRedactor
+
+// DefaultParamRedactor redacts the values of keys containing
+// password, token or secret, ignoring case
+func DefaultParamRedactor(key string, value interface{}) interface{} {
+	key = strings.ToLower(key)
+	for _, sensitive := range []string{"password", "token", "secret"} {
+		if strings.Contains(key, sensitive) {
+			return "<redacted>"
+		}
+	}
+	return value
+}
+
+// redactParams gets a copy of the params with ParamRedactor applied,
+// including to the maps nested in them, even within lists
+func redactParams(params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+
+	output := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		output[k] = ParamRedactor(k, redactNested(v))
+	}
+	return output
+}
+
+// redactNested redacts the maps within a param value, copying any lists
+// containing them
+func redactNested(param interface{}) interface{} {
+	switch param := param.(type) {
+	case map[string]interface{}:
+		return redactParams(param)
+	case []interface{}:
+		output := make([]interface{}, len(param))
+		for i, item := range param {
+			output[i] = redactNested(item)
+		}
+		return output
+	}
+
+	// Other slices that may hold maps, such as []map[string]interface{}
+	v := reflect.ValueOf(param)
+	if v.Kind() == reflect.Slice {
+		switch v.Type().Elem().Kind() {
+		case reflect.Map, reflect.Slice, reflect.Interface:
+			output := make([]interface{}, v.Len())
+			for i := range output {
+				output[i] = redactNested(v.Index(i).Interface())
+			}
+			return output
+		}
+	}
+	return param
+}
+
+// NormalizeParams converts a map with interface{} keys, such as those
+// decoded from YAML, into a parameter map that can be passed to the
+// driver.  Nested maps and slices are converted as well.  All keys
+// must be strings at runtime, or an error is returned with the offending key.
+func NormalizeParams(params map[interface{}]interface{}) (map[string]interface{}, error) {
+	output := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		key, ok := k.(string)
+		if !ok {
+			return nil, errors.New("Parameter key must be a string. Got %T %+v", k, k)
+		}
+
+		val, err := normalizeParam(v)
+		if err != nil {
+			return nil, errors.Wrap(err, "An error occurred normalizing parameter %s", key)
+		}
+		output[key] = val
+	}
+	return output, nil
+}
+
+func normalizeParam(param interface{}) (interface{}, error) {
+	switch param := param.(type) {
+	case map[interface{}]interface{}:
+		return NormalizeParams(param)
+	case []interface{}:
+		output := make([]interface{}, len(param))
+		for i, item := range param {
+			val, err := normalizeParam(item)
+			if err != nil {
+				return nil, err
+			}
+			output[i] = val
+		}
+		return output, nil
+	default:
+		return param, nil
+	}
+}
+
+// ValidateParams checks that all of the parameters can be encoded, without
+// sending anything to the server.  Returns the first encoding error, with the
+// offending key.  Keys are checked in sorted order.
+func ValidateParams(params map[string]interface{}) error {
+	return validateParams(encoding.NewEncoder(ioutil.Discard, math.MaxUint16), params)
+}
+
+// validateParams checks that all of the parameters can be encoded by the
+// encoder, which should discard what it writes
+func validateParams(encoder encoding.Encoder, params map[string]interface{}) error {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := encoder.Encode(params[key]); err != nil {
+			return errors.Wrap(err, "Parameter %s can't be encoded", key)
+		}
+	}
+	return nil
+}
+
+// Bookmarks builds a list of bookmarks that can be passed as the "bookmarks"
+// parameter of a BEGIN statement, to make the transaction wait until the
+// server has caught up to them.  Errors if any of the bookmarks are empty.
+func Bookmarks(bookmarks ...string) ([]interface{}, error) {
+	output := make([]interface{}, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		if bookmark == "" {
+			return nil, errors.New("Bookmark %d is empty", i)
+		}
+		output[i] = bookmark
+	}
+	return output, nil
+}
+
+// ParamsFromValues builds a parameter map from form or query values, such as
+// from an http request, converting each value in the spec to the given kind.
+// Supported kinds are Int and Int64 (to int64), Float32 and Float64 (to float64),
+// Bool and String.  Values missing from v are left out of the parameters.
+// If any values can't be converted, the error lists each of their fields.
+func ParamsFromValues(v url.Values, spec map[string]reflect.Kind) (map[string]interface{}, error) {
+	fields := make([]string, 0, len(spec))
+	for field := range spec {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	params := make(map[string]interface{}, len(spec))
+	invalid := []string{}
+	for _, field := range fields {
+		if _, ok := v[field]; !ok {
+			continue
+		}
+
+		param, err := paramFromValue(v.Get(field), spec[field])
+		if err != nil {
+			invalid = append(invalid, field+": "+err.Error())
+			continue
+		}
+		params[field] = param
+	}
+
+	if len(invalid) > 0 {
+		return nil, errors.New("Invalid parameter values. %s", strings.Join(invalid, "; "))
+	}
+	return params, nil
+}
+
+func paramFromValue(value string, kind reflect.Kind) (interface{}, error) {
+	switch kind {
+	case reflect.Int, reflect.Int64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.String:
+		return value, nil
+	default:
+		return nil, errors.New("Unsupported parameter kind %s", kind)
+	}
+}
@@ -0,0 +1,251 @@
+package golangNeo4jBoltDriver
+
+import (
+	"bytes"
+	"fmt"
+	stdlog "log"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/encoding"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/log"
+)
+
+func TestNormalizeParams(t *testing.T) {
+	params, err := NormalizeParams(map[interface{}]interface{}{
+		"a": 1,
+		"b": map[interface{}]interface{}{"c": "foo"},
+		"d": []interface{}{map[interface{}]interface{}{"e": true}},
+	})
+	if err != nil {
+		t.Fatalf("An error occurred normalizing params: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{"c": "foo"},
+		"d": []interface{}{map[string]interface{}{"e": true}},
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("Unexpected normalized params. Expected %#v. Got: %#v", expected, params)
+	}
+}
+
+func TestNormalizeParams_NonStringKey(t *testing.T) {
+	_, err := NormalizeParams(map[interface{}]interface{}{"a": 1, 2: "b"})
+	if err == nil {
+		t.Fatal("Expected error from non-string key")
+	}
+
+	_, err = NormalizeParams(map[interface{}]interface{}{
+		"a": map[interface{}]interface{}{true: "b"},
+	})
+	if err == nil {
+		t.Fatal("Expected error from nested non-string key")
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	err := ValidateParams(map[string]interface{}{
+		"a": 1,
+		"b": "foo",
+		"c": []interface{}{1.5, true, nil},
+		"d": map[string]interface{}{"e": []string{"f"}},
+	})
+	if err != nil {
+		t.Fatalf("An error occurred validating encodable params: %s", err)
+	}
+}
+
+func TestValidateParams_Unencodable(t *testing.T) {
+	err := ValidateParams(map[string]interface{}{
+		"a":   1,
+		"bad": make(chan int),
+	})
+	if err == nil {
+		t.Fatal("Expected error validating unencodable param")
+	}
+	if !strings.Contains(err.Error(), "bad") {
+		t.Fatalf("Expected error to include the offending key. Got: %s", err)
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	bookmarks, err := Bookmarks("neo4j:bookmark:v1:tx1", "neo4j:bookmark:v1:tx2")
+	if err != nil {
+		t.Fatalf("An error occurred building bookmarks: %s", err)
+	}
+
+	encoded, err := encoding.Marshal(bookmarks)
+	if err != nil {
+		t.Fatalf("An error occurred encoding bookmarks: %s", err)
+	}
+	decoded, err := encoding.Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding bookmarks: %s", err)
+	}
+
+	expected := []interface{}{"neo4j:bookmark:v1:tx1", "neo4j:bookmark:v1:tx2"}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("Expected bookmarks to encode as a string list. Expected %#v. Got: %#v", expected, decoded)
+	}
+}
+
+func TestBookmarks_Empty(t *testing.T) {
+	if _, err := Bookmarks("neo4j:bookmark:v1:tx1", ""); err == nil {
+		t.Fatal("Expected error building bookmarks with an empty bookmark")
+	}
+}
+
+func TestParamsFromValues(t *testing.T) {
+	values := url.Values{
+		"limit":  {"10"},
+		"score":  {"2.5"},
+		"active": {"true"},
+		"name":   {"foo"},
+		"extra":  {"ignored"},
+	}
+	spec := map[string]reflect.Kind{
+		"limit":   reflect.Int,
+		"score":   reflect.Float64,
+		"active":  reflect.Bool,
+		"name":    reflect.String,
+		"missing": reflect.Int,
+	}
+
+	params, err := ParamsFromValues(values, spec)
+	if err != nil {
+		t.Fatalf("An error occurred converting values: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"limit":  int64(10),
+		"score":  2.5,
+		"active": true,
+		"name":   "foo",
+	}
+	if !reflect.DeepEqual(params, expected) {
+		t.Fatalf("Unexpected params. Expected %#v. Got: %#v", expected, params)
+	}
+}
+
+func TestParamsFromValues_Malformed(t *testing.T) {
+	values := url.Values{"limit": {"ten"}, "name": {"foo"}}
+	spec := map[string]reflect.Kind{"limit": reflect.Int, "name": reflect.String}
+
+	_, err := ParamsFromValues(values, spec)
+	if err == nil {
+		t.Fatal("Expected error converting malformed int")
+	}
+	if !strings.Contains(err.Error(), "limit") {
+		t.Fatalf("Expected error to include the malformed field. Got: %s", err)
+	}
+}
+
+func TestParamRedactor(t *testing.T) {
+	logged := &bytes.Buffer{}
+	infoLog := log.InfoLog
+	log.InfoLog = stdlog.New(logged, "", 0)
+	log.SetLevel("info")
+	defer func() {
+		log.InfoLog = infoLog
+		log.SetLevel(os.Getenv("BOLT_DRIVER_LOG"))
+	}()
+
+	c := createBoltConn("")
+	c.conn = &fakeConn{}
+	params := map[string]interface{}{
+		"name":     "visible-name",
+		"password": "hunter2",
+		"auth":     map[string]interface{}{"apiToken": "abc123"},
+	}
+	if err := c.sendRun("CREATE (u:User {name: $name, password: $password})", params); err != nil {
+		t.Fatalf("An error occurred sending run: %s", err)
+	}
+
+	if !strings.Contains(logged.String(), "visible-name") {
+		t.Fatalf("Expected unredacted param to be logged. Got: %s", logged.String())
+	}
+	if strings.Contains(logged.String(), "hunter2") || strings.Contains(logged.String(), "abc123") {
+		t.Fatalf("Expected sensitive params to be redacted. Got: %s", logged.String())
+	}
+	if params["password"] != "hunter2" {
+		t.Fatal("Expected redaction not to modify the params sent")
+	}
+
+	logged.Reset()
+	redactor := ParamRedactor
+	ParamRedactor = func(key string, value interface{}) interface{} {
+		if key == "name" {
+			return "***"
+		}
+		return value
+	}
+	defer func() { ParamRedactor = redactor }()
+
+	if err := c.sendRun("RETURN $name", map[string]interface{}{"name": "visible-name"}); err != nil {
+		t.Fatalf("An error occurred sending run: %s", err)
+	}
+	if strings.Contains(logged.String(), "visible-name") {
+		t.Fatalf("Expected custom redactor to be used. Got: %s", logged.String())
+	}
+}
+
+func TestParamRedactor_Nested(t *testing.T) {
+	params := map[string]interface{}{
+		"users":  []interface{}{map[string]interface{}{"name": "a", "password": "hunter2"}},
+		"tokens": []map[string]interface{}{{"apiToken": "abc123"}},
+		"ids":    []int{1, 2},
+	}
+
+	redacted := redactParams(params)
+	expected := map[string]interface{}{
+		"users":  []interface{}{map[string]interface{}{"name": "a", "password": "<redacted>"}},
+		"tokens": "<redacted>",
+		"ids":    []int{1, 2},
+	}
+	if !reflect.DeepEqual(redacted, expected) {
+		t.Fatalf("Unexpected redacted params. Expected %#v. Got: %#v", expected, redacted)
+	}
+
+	logged := redactParams(map[string]interface{}{"batch": []map[string]interface{}{{"secret": "s3cr3t"}}})
+	if strings.Contains(fmt.Sprintf("%#v", logged), "s3cr3t") {
+		t.Fatalf("Expected maps in typed slices to be redacted. Got: %#v", logged)
+	}
+	if params["users"].([]interface{})[0].(map[string]interface{})["password"] != "hunter2" {
+		t.Fatal("Expected redaction not to modify the params")
+	}
+}
+
+func TestParamRedactor_EncodeError(t *testing.T) {
+	logged := &bytes.Buffer{}
+	infoLog := log.InfoLog
+	log.InfoLog = stdlog.New(logged, "", 0)
+	log.SetLevel("info")
+	defer func() {
+		log.InfoLog = infoLog
+		log.SetLevel(os.Getenv("BOLT_DRIVER_LOG"))
+	}()
+
+	c := createBoltConn("")
+	c.conn = newFakeConn(t)
+	params := map[string]interface{}{
+		"password": "hunter2",
+		"users":    []interface{}{map[string]interface{}{"token": "abc123"}},
+		"bad":      make(chan int),
+	}
+
+	_, err := c.QueryNeo("CREATE (u:User {password: $password}) RETURN u", params)
+	if err == nil {
+		t.Fatal("Expected error querying with an unencodable param")
+	}
+	if strings.Contains(err.Error(), "hunter2") || strings.Contains(err.Error(), "abc123") {
+		t.Fatalf("Expected sensitive params not to be included in the encode error. Got: %s", err)
+	}
+	if strings.Contains(logged.String(), "hunter2") || strings.Contains(logged.String(), "abc123") {
+		t.Fatalf("Expected sensitive params to be redacted in the query log. Got: %s", logged.String())
+	}
+}
@@ -140,7 +140,13 @@ func (r *recorder) Write(b []byte) (n int, err error) {
 
 	for i := 0; i < len(b); i++ {
 		if len(event.Event) == 0 {
-			return i, errors.New("Attempted to write past current event in recorder! %#v, Event: %#v", r, event)
+			// Multiple messages may be batched into a single write,
+			// so continue on to the next event if it is also a write
+			if r.currentEvent+1 >= len(r.events) || !r.events[r.currentEvent+1].IsWrite {
+				return i, errors.New("Attempted to write past current event in recorder! %#v, Event: %#v", r, event)
+			}
+			r.currentEvent++
+			event = r.events[r.currentEvent]
 		}
 		event.Event = event.Event[1:]
 	}
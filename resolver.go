@@ -0,0 +1,73 @@
+package golangNeo4jBoltDriver
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+)
+
+// dnsCache is shared by all connections that enable dns_cache_ttl, so
+// connections to the same host reuse each others lookups
+var dnsCache = newResolverCache(net.LookupHost)
+
+type resolverEntry struct {
+	addrs   []string
+	expires time.Time
+	next    int
+}
+
+// resolverCache caches the addresses a host resolves to, and rotates
+// between them for each dial
+type resolverCache struct {
+	mu      sync.Mutex
+	entries map[string]*resolverEntry
+	lookup  func(host string) ([]string, error)
+	now     func() time.Time
+}
+
+func newResolverCache(lookup func(host string) ([]string, error)) *resolverCache {
+	return &resolverCache{
+		entries: map[string]*resolverEntry{},
+		lookup:  lookup,
+		now:     time.Now,
+	}
+}
+
+// resolve returns the address to dial for the given host:port, resolving
+// the host if it isn't cached or the cached addresses are older than ttl.
+// IP addresses are returned as they are.
+func (r *resolverCache) resolve(hostport string, ttl time.Duration) (string, error) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "", errors.Wrap(err, "An error occurred splitting host and port: %s", hostport)
+	}
+	if net.ParseIP(host) != nil {
+		return hostport, nil
+	}
+
+	r.mu.Lock()
+	entry, ok := r.entries[host]
+	if !ok || !r.now().Before(entry.expires) {
+		// Lookups can be slow, so don't hold up resolving other hosts.
+		// Concurrent lookups of the same host may both run, and the
+		// last one to finish is cached.
+		r.mu.Unlock()
+		addrs, err := r.lookup(host)
+		if err != nil {
+			return "", errors.Wrap(err, "An error occurred resolving host: %s", host)
+		} else if len(addrs) == 0 {
+			return "", errors.New("No addresses found for host: %s", host)
+		}
+
+		r.mu.Lock()
+		entry = &resolverEntry{addrs: addrs, expires: r.now().Add(ttl)}
+		r.entries[host] = entry
+	}
+	defer r.mu.Unlock()
+
+	addr := entry.addrs[entry.next%len(entry.addrs)]
+	entry.next++
+	return net.JoinHostPort(addr, port), nil
+}
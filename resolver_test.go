@@ -0,0 +1,109 @@
+package golangNeo4jBoltDriver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolverCache_TTL(t *testing.T) {
+	lookups := 0
+	r := newResolverCache(func(host string) ([]string, error) {
+		lookups++
+		return []string{"10.0.0.1"}, nil
+	})
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		addr, err := r.resolve("neo4j:7687", time.Minute)
+		if err != nil {
+			t.Fatalf("An error occurred resolving: %s", err)
+		}
+		if addr != "10.0.0.1:7687" {
+			t.Fatalf("Unexpected address: %s", addr)
+		}
+	}
+	if lookups != 1 {
+		t.Fatalf("Expected 1 lookup within the TTL. Got: %d", lookups)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := r.resolve("neo4j:7687", time.Minute); err != nil {
+		t.Fatalf("An error occurred resolving: %s", err)
+	}
+	if lookups != 2 {
+		t.Fatalf("Expected another lookup after the TTL. Got: %d", lookups)
+	}
+}
+
+func TestResolverCache_Rotation(t *testing.T) {
+	r := newResolverCache(func(host string) ([]string, error) {
+		return []string{"10.0.0.1", "10.0.0.2", "::1"}, nil
+	})
+
+	counts := map[string]int{}
+	for i := 0; i < 6; i++ {
+		addr, err := r.resolve("neo4j:7687", time.Minute)
+		if err != nil {
+			t.Fatalf("An error occurred resolving: %s", err)
+		}
+		counts[addr]++
+	}
+
+	for _, addr := range []string{"10.0.0.1:7687", "10.0.0.2:7687", "[::1]:7687"} {
+		if counts[addr] != 2 {
+			t.Fatalf("Expected 2 connections to %s. Got: %#v", addr, counts)
+		}
+	}
+}
+
+func TestResolverCache_SlowLookup(t *testing.T) {
+	slow := make(chan struct{})
+	r := newResolverCache(func(host string) ([]string, error) {
+		if host == "slow" {
+			<-slow
+		}
+		return []string{"10.0.0.1"}, nil
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := r.resolve("slow:7687", time.Minute)
+		done <- err
+	}()
+
+	// Resolving another host isn't blocked by the slow lookup
+	resolved := make(chan error)
+	go func() {
+		_, err := r.resolve("fast:7687", time.Minute)
+		resolved <- err
+	}()
+	select {
+	case err := <-resolved:
+		if err != nil {
+			t.Fatalf("An error occurred resolving: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected resolving a host not to wait for a slow lookup of another")
+	}
+
+	close(slow)
+	if err := <-done; err != nil {
+		t.Fatalf("An error occurred resolving slow host: %s", err)
+	}
+}
+
+func TestResolverCache_IP(t *testing.T) {
+	r := newResolverCache(func(host string) ([]string, error) {
+		t.Fatalf("Should not look up an IP address: %s", host)
+		return nil, nil
+	})
+
+	addr, err := r.resolve("127.0.0.1:7687", time.Minute)
+	if err != nil {
+		t.Fatalf("An error occurred resolving: %s", err)
+	}
+	if addr != "127.0.0.1:7687" {
+		t.Fatalf("Unexpected address: %s", addr)
+	}
+}
@@ -1,6 +1,10 @@
 package golangNeo4jBoltDriver
 
-import "github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+import (
+	"time"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+)
 
 // Result represents a result from a query that returns no data
 type Result interface {
@@ -13,14 +17,63 @@ type Result interface {
 	RowsAffected() (int64, error)
 	// Metadata returns the metadata response from neo4j
 	Metadata() map[string]interface{}
+	// RoundTripTime returns the time measured by the client from sending
+	// the query to getting its final SUCCESS.  Unlike the timings reported
+	// by the server, this includes the time spent on the network.
+	RoundTripTime() time.Duration
+	// Counters returns the stats counters from the metadata
+	Counters() Counters
+}
+
+// Counters are the counts of the changes a query made, from the
+// stats in its metadata.  Counters the server didn't send are 0.
+type Counters struct {
+	NodesCreated         int64
+	NodesDeleted         int64
+	RelationshipsCreated int64
+	RelationshipsDeleted int64
+	PropertiesSet        int64
+	LabelsAdded          int64
+	LabelsRemoved        int64
+	IndexesAdded         int64
+	IndexesRemoved       int64
+	ConstraintsAdded     int64
+	ConstraintsRemoved   int64
+	SystemUpdates        int64
+}
+
+// NewCounters gets the counters from the stats in the metadata
+// of a query's final SUCCESS
+func NewCounters(metadata map[string]interface{}) Counters {
+	stats, _ := metadata["stats"].(map[string]interface{})
+	counter := func(key string) int64 {
+		count, _ := stats[key].(int64)
+		return count
+	}
+
+	return Counters{
+		NodesCreated:         counter("nodes-created"),
+		NodesDeleted:         counter("nodes-deleted"),
+		RelationshipsCreated: counter("relationships-created"),
+		RelationshipsDeleted: counter("relationships-deleted"),
+		PropertiesSet:        counter("properties-set"),
+		LabelsAdded:          counter("labels-added"),
+		LabelsRemoved:        counter("labels-removed"),
+		IndexesAdded:         counter("indexes-added"),
+		IndexesRemoved:       counter("indexes-removed"),
+		ConstraintsAdded:     counter("constraints-added"),
+		ConstraintsRemoved:   counter("constraints-removed"),
+		SystemUpdates:        counter("system-updates"),
+	}
 }
 
 type boltResult struct {
-	metadata map[string]interface{}
+	metadata      map[string]interface{}
+	roundTripTime time.Duration
 }
 
-func newResult(metadata map[string]interface{}) boltResult {
-	return boltResult{metadata: metadata}
+func newResult(metadata map[string]interface{}, roundTripTime time.Duration) boltResult {
+	return boltResult{metadata: metadata, roundTripTime: roundTripTime}
 }
 
 // Returns the response metadata from the bolt success message
@@ -28,6 +81,35 @@ func (r boltResult) Metadata() map[string]interface{} {
 	return r.metadata
 }
 
+// RoundTripTime returns the time measured by the client from
+// sending the query to getting its final SUCCESS
+func (r boltResult) RoundTripTime() time.Duration {
+	return r.roundTripTime
+}
+
+// add gets the sum of the counters
+func (c Counters) add(other Counters) Counters {
+	return Counters{
+		NodesCreated:         c.NodesCreated + other.NodesCreated,
+		NodesDeleted:         c.NodesDeleted + other.NodesDeleted,
+		RelationshipsCreated: c.RelationshipsCreated + other.RelationshipsCreated,
+		RelationshipsDeleted: c.RelationshipsDeleted + other.RelationshipsDeleted,
+		PropertiesSet:        c.PropertiesSet + other.PropertiesSet,
+		LabelsAdded:          c.LabelsAdded + other.LabelsAdded,
+		LabelsRemoved:        c.LabelsRemoved + other.LabelsRemoved,
+		IndexesAdded:         c.IndexesAdded + other.IndexesAdded,
+		IndexesRemoved:       c.IndexesRemoved + other.IndexesRemoved,
+		ConstraintsAdded:     c.ConstraintsAdded + other.ConstraintsAdded,
+		ConstraintsRemoved:   c.ConstraintsRemoved + other.ConstraintsRemoved,
+		SystemUpdates:        c.SystemUpdates + other.SystemUpdates,
+	}
+}
+
+// Counters returns the stats counters from the metadata
+func (r boltResult) Counters() Counters {
+	return NewCounters(r.metadata)
+}
+
 // LastInsertId gets the last inserted id. This will always return -1.
 func (r boltResult) LastInsertId() (int64, error) {
 	// TODO: Is this possible?
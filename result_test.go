@@ -0,0 +1,32 @@
+package golangNeo4jBoltDriver
+
+import (
+	"testing"
+)
+
+func TestBoltResult_Counters(t *testing.T) {
+	result := newResult(map[string]interface{}{
+		"stats": map[string]interface{}{
+			"nodes-created":   int64(3),
+			"properties-set":  int64(6),
+			"labels-added":    int64(3),
+			"indexes-removed": int64(1),
+			"system-updates":  int64(2),
+		},
+	}, 0)
+
+	expected := Counters{
+		NodesCreated:   3,
+		PropertiesSet:  6,
+		LabelsAdded:    3,
+		IndexesRemoved: 1,
+		SystemUpdates:  2,
+	}
+	if counters := result.Counters(); counters != expected {
+		t.Fatalf("Unexpected counters. Expected: %#v. Got: %#v", expected, counters)
+	}
+
+	if counters := newResult(map[string]interface{}{}, 0).Counters(); counters != (Counters{}) {
+		t.Fatalf("Expected zero counters without stats. Got: %#v", counters)
+	}
+}
@@ -2,7 +2,11 @@ package golangNeo4jBoltDriver
 
 import (
 	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
 	"io"
+	"strconv"
+	"time"
 
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/encoding"
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
@@ -21,15 +25,58 @@ type Rows interface {
 	Columns() []string
 	// Metadata Gets all of the metadata returned from Neo on query start
 	Metadata() map[string]interface{}
+	// RunMetadata Gets the metadata returned from Neo on query start as a RunMetadata
+	RunMetadata() RunMetadata
 	// Close the rows, flushing any existing datastream
 	Close() error
 	// NextNeo gets the next row result
 	// When the rows are completed, returns the success metadata
 	// and io.EOF
 	NextNeo() ([]interface{}, map[string]interface{}, error)
+	// NextInto gets the next row result, decoding it into dst, which is
+	// grown if it doesn't have the capacity for the row.  Returns the row,
+	// which shares dst's backing array, so it's overwritten by the next
+	// call reusing dst.  Copy the row if it needs to be kept.  Values in the
+	// row, including nested maps, slices and structures, aren't reused.
+	// When the rows are completed, returns io.EOF, and NextNeo can be
+	// called to get the success metadata.
+	NextInto(dst []interface{}) ([]interface{}, error)
 	// All gets all of the results from the row set. It's recommended to use NextNeo when
 	// there are a lot of rows
 	All() ([][]interface{}, map[string]interface{}, error)
+	// ForEach calls the given function with each row result as it is streamed.
+	// Stops at, and returns, the first error from the function or the stream
+	ForEach(func(row []interface{}) error) error
+	// Peek gets the next row result without consuming it, so it will still
+	// be returned by the next call to NextNeo. Returns false if there are no more rows.
+	Peek() ([]interface{}, bool, error)
+	// ColumnValues gets all of the values in the named column, reading
+	// all of the remaining rows
+	ColumnValues(name string) ([]interface{}, error)
+	// WriteCSV writes all of the remaining rows to w as CSV, returning
+	// the number of rows written
+	WriteCSV(w io.Writer, opts CSVOptions) (int, error)
+	// Graph gets all of the nodes and relationships in the remaining rows,
+	// including those in paths, lists and maps, deduplicated by identity
+	Graph() (graph.Graph, error)
+}
+
+// CSVOptions are the options for writing rows as CSV
+type CSVOptions struct {
+	// Delimiter is the field delimiter. Defaults to ','
+	Delimiter rune
+	// NoHeader skips writing the header row of column names
+	NoHeader bool
+}
+
+// RowLimit limits the number of rows read from a query
+type RowLimit struct {
+	// MaxRows is the most rows to return.  The rest are read from the
+	// connection and discarded.
+	MaxRows int
+	// Truncate stops cleanly with io.EOF after MaxRows rows.  Defaults to
+	// returning an errors.RowLimitError if there are more rows.
+	Truncate bool
 }
 
 // PipelineRows represents results of a set of rows from the DB
@@ -52,6 +99,37 @@ type PipelineRows interface {
 	NextPipeline() ([]interface{}, map[string]interface{}, PipelineRows, error)
 }
 
+// RunMetadata holds the metadata returned from Neo on query start
+type RunMetadata struct {
+	// Fields are the names of the columns in the returned dataset
+	Fields []string
+	// QID is the id of the query within a transaction, or -1 if the server didn't send one
+	QID int64
+	// AvailableAfter is how long the server took before the result was
+	// available, from either result_available_after or t_first
+	AvailableAfter time.Duration
+}
+
+func newRunMetadata(metadata map[string]interface{}) RunMetadata {
+	runMetadata := RunMetadata{
+		Fields: fieldsToStrings(metadata),
+		QID:    -1,
+	}
+
+	if qid, ok := metadata["qid"].(int64); ok {
+		runMetadata.QID = qid
+	}
+
+	// Newer protocol versions name it t_first
+	if availableAfter, ok := metadata["result_available_after"].(int64); ok {
+		runMetadata.AvailableAfter = time.Duration(availableAfter) * time.Millisecond
+	} else if tFirst, ok := metadata["t_first"].(int64); ok {
+		runMetadata.AvailableAfter = time.Duration(tFirst) * time.Millisecond
+	}
+
+	return runMetadata
+}
+
 type boltRows struct {
 	metadata        map[string]interface{}
 	statement       *boltStmt
@@ -59,7 +137,13 @@ type boltRows struct {
 	consumed        bool
 	finishedConsume bool
 	pipelineIndex   int
+	columns         []string
 	closeStatement  bool
+	buffered        [][]interface{}
+	eof             bool
+	finalMetadata   map[string]interface{}
+	limit           RowLimit
+	rowsRead        int
 }
 
 func newRows(statement *boltStmt, metadata map[string]interface{}) *boltRows {
@@ -89,9 +173,25 @@ func newQueryPipelineRows(statement *boltStmt, metadata map[string]interface{},
 	return rows
 }
 
-// Columns returns the columns from the result
+// Columns returns the columns from the result.  The names are parsed from
+// the metadata once, and a copy is returned, so callers can't modify them.
 func (r *boltRows) Columns() []string {
-	fieldsInt, ok := r.metadata["fields"]
+	if r.columns == nil {
+		r.columns = fieldsToStrings(r.metadata)
+	}
+	columns := make([]string, len(r.columns))
+	copy(columns, r.columns)
+	return columns
+}
+
+// RunMetadata Gets the metadata returned from Neo on query start as a RunMetadata
+func (r *boltRows) RunMetadata() RunMetadata {
+	return newRunMetadata(r.metadata)
+}
+
+// fieldsToStrings gets the column names from the fields in the metadata
+func fieldsToStrings(metadata map[string]interface{}) []string {
+	fieldsInt, ok := metadata["fields"]
 	if !ok {
 		return []string{}
 	}
@@ -132,12 +232,12 @@ func (r *boltRows) Close() error {
 
 		switch resp := respInt.(type) {
 		case messages.SuccessMessage:
-			log.Infof("Got success message: %#v", resp)
+			log.Infof("[%s] Got success message: %#v", r.statement.conn.ID(), resp)
 		default:
 			return errors.New("Unrecognized response type discarding all rows: Value: %#v", resp)
 		}
 
-	} else if !r.finishedConsume {
+	} else if !r.finishedConsume && !r.statement.conn.broken {
 		// If this is a pipeline statement, we need to "consume all" multiple times
 		numConsume := 1
 		if r.statement.queries != nil {
@@ -203,6 +303,89 @@ func (r *boltRows) NextNeo() ([]interface{}, map[string]interface{}, error) {
 		return nil, nil, errors.New("Rows are already closed")
 	}
 
+	if len(r.buffered) > 0 {
+		row := r.buffered[0]
+		r.buffered = r.buffered[1:]
+		return row, nil, nil
+	} else if r.eof {
+		return nil, r.finalMetadata, io.EOF
+	}
+
+	return r.nextNeo()
+}
+
+// Peek gets the next row result without consuming it, so it will still
+// be returned by the next call to NextNeo. Returns false if there are no more rows.
+func (r *boltRows) Peek() ([]interface{}, bool, error) {
+	if r.closed {
+		return nil, false, errors.New("Rows are already closed")
+	}
+
+	if len(r.buffered) > 0 {
+		return r.buffered[0], true, nil
+	} else if r.eof {
+		return nil, false, nil
+	}
+
+	row, metadata, err := r.nextNeo()
+	if err == io.EOF {
+		r.eof = true
+		r.finalMetadata = metadata
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	r.buffered = append(r.buffered, row)
+	return row, true, nil
+}
+
+// buffer reads up to threshold rows into memory.  If all of the rows fit,
+// the rows are released from the connection so it can be used for other
+// queries. Otherwise, the rest of the rows are streamed after the buffer.
+func (r *boltRows) buffer(threshold int) error {
+	for len(r.buffered) <= threshold {
+		row, metadata, err := r.nextNeo()
+		if err == io.EOF {
+			r.eof = true
+			r.finalMetadata = metadata
+			return r.statement.Close()
+		} else if err != nil {
+			return err
+		}
+		r.buffered = append(r.buffered, row)
+	}
+
+	return nil
+}
+
+// NextInto gets the next row result, decoding it into dst
+func (r *boltRows) NextInto(dst []interface{}) ([]interface{}, error) {
+	if r.closed {
+		return nil, errors.New("Rows are already closed")
+	}
+
+	if len(r.buffered) > 0 {
+		row := append(dst[:0], r.buffered[0]...)
+		r.buffered = r.buffered[1:]
+		return row, nil
+	} else if r.eof {
+		return nil, io.EOF
+	}
+
+	row, metadata, err := r.nextNeoInto(dst[:0])
+	if err == io.EOF {
+		r.eof = true
+		r.finalMetadata = metadata
+	}
+	return row, err
+}
+
+func (r *boltRows) nextNeo() ([]interface{}, map[string]interface{}, error) {
+	return r.nextNeoInto(nil)
+}
+
+func (r *boltRows) nextNeoInto(dst []interface{}) ([]interface{}, map[string]interface{}, error) {
 	if !r.consumed {
 		r.consumed = true
 		if err := r.statement.conn.sendPullAll(); err != nil {
@@ -211,24 +394,48 @@ func (r *boltRows) NextNeo() ([]interface{}, map[string]interface{}, error) {
 		}
 	}
 
-	respInt, err := r.statement.conn.consume()
+	respInt, err := r.statement.conn.consumeInto(dst)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	switch resp := respInt.(type) {
 	case messages.SuccessMessage:
-		log.Infof("Got success message: %#v", resp)
+		log.Infof("[%s] Got success message: %#v", r.statement.conn.ID(), resp)
 		r.finishedConsume = true
 		return nil, resp.Metadata, io.EOF
 	case messages.RecordMessage:
-		log.Infof("Got record message: %#v", resp)
+		log.Infof("[%s] Got record message: %#v", r.statement.conn.ID(), resp)
+		if r.limit.MaxRows > 0 && r.rowsRead >= r.limit.MaxRows {
+			return r.discardOverLimit()
+		}
+		r.rowsRead++
 		return resp.Fields, nil, nil
 	default:
 		return nil, nil, errors.New("Unrecognized response type getting next query row: %#v", resp)
 	}
 }
 
+// discardOverLimit reads and discards the rest of the rows once there are
+// more than the row limit.  PULL_ALL has already asked for all of the rows,
+// so they have to be read to free the connection.
+func (r *boltRows) discardOverLimit() ([]interface{}, map[string]interface{}, error) {
+	log.Infof("[%s] Discarding rows over the limit of %d", r.statement.conn.ID(), r.limit.MaxRows)
+	_, successInt, err := r.statement.conn.consumeAll()
+	r.finishedConsume = true
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "An error occurred discarding rows over the limit")
+	}
+
+	success, _ := successInt.(messages.SuccessMessage)
+	r.eof = true
+	r.finalMetadata = success.Metadata
+	if r.limit.Truncate {
+		return nil, success.Metadata, io.EOF
+	}
+	return nil, success.Metadata, errors.NewRowLimitError(r.limit.MaxRows)
+}
+
 func (r *boltRows) All() ([][]interface{}, map[string]interface{}, error) {
 	output := [][]interface{}{}
 	for {
@@ -243,6 +450,129 @@ func (r *boltRows) All() ([][]interface{}, map[string]interface{}, error) {
 	}
 }
 
+// ForEach calls the given function with each row result as it is streamed.
+// Stops at, and returns, the first error from the function or the stream
+func (r *boltRows) ForEach(fn func(row []interface{}) error) error {
+	for {
+		row, _, err := r.NextNeo()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// ColumnValues gets all of the values in the named column, reading
+// all of the remaining rows
+func (r *boltRows) ColumnValues(name string) ([]interface{}, error) {
+	idx := -1
+	for i, column := range r.Columns() {
+		if column == name {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return nil, errors.New("Unknown column %s. Columns are: %v", name, r.Columns())
+	}
+
+	values := []interface{}{}
+	err := r.ForEach(func(row []interface{}) error {
+		if idx >= len(row) {
+			return errors.New("Row is missing column %s: %#v", name, row)
+		}
+		values = append(values, row[idx])
+		return nil
+	})
+	return values, err
+}
+
+// Graph gets all of the nodes and relationships in the remaining rows,
+// including those in paths, lists and maps, deduplicated by identity
+func (r *boltRows) Graph() (graph.Graph, error) {
+	g := graph.NewGraph()
+	err := r.ForEach(func(row []interface{}) error {
+		for _, val := range row {
+			g.Add(val)
+		}
+		return nil
+	})
+	return g, err
+}
+
+// WriteCSV writes all of the remaining rows to w as CSV, returning
+// the number of rows written.  Primitives are written as strings, and
+// nodes, relationships, paths, lists and maps are written as the JSON of
+// their ToJSON form.  The rows written before an error are still flushed to w.
+func (r *boltRows) WriteCSV(w io.Writer, opts CSVOptions) (int, error) {
+	writer := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		writer.Comma = opts.Delimiter
+	}
+
+	count, err := r.writeCSVRows(writer, opts)
+
+	writer.Flush()
+	if flushErr := writer.Error(); flushErr != nil && err == nil {
+		err = errors.Wrap(flushErr, "An error occurred flushing CSV")
+	}
+	return count, err
+}
+
+// writeCSVRows writes the header and rows to the CSV writer, without flushing it
+func (r *boltRows) writeCSVRows(writer *csv.Writer, opts CSVOptions) (int, error) {
+	if !opts.NoHeader {
+		if err := writer.Write(r.Columns()); err != nil {
+			return 0, errors.Wrap(err, "An error occurred writing CSV header")
+		}
+	}
+
+	count := 0
+	err := r.ForEach(func(row []interface{}) error {
+		record := make([]string, len(row))
+		for i, val := range row {
+			field, err := csvField(val)
+			if err != nil {
+				return err
+			}
+			record[i] = field
+		}
+
+		if err := writer.Write(record); err != nil {
+			return errors.Wrap(err, "An error occurred writing CSV row")
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+func csvField(val interface{}) (string, error) {
+	switch val := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return val, nil
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	default:
+		b, err := json.Marshal(ToJSON(val))
+		if err != nil {
+			return "", errors.Wrap(err, "An error occurred converting %T to JSON for CSV", val)
+		}
+		return string(b), nil
+	}
+}
+
 // NextPipeline gets the next row result
 // When the rows are completed, returns the success metadata and the next
 // set of rows.
@@ -259,7 +589,7 @@ func (r *boltRows) NextPipeline() ([]interface{}, map[string]interface{}, Pipeli
 
 	switch resp := respInt.(type) {
 	case messages.SuccessMessage:
-		log.Infof("Got success message: %#v", resp)
+		log.Infof("[%s] Got success message: %#v", r.statement.conn.ID(), resp)
 
 		if r.pipelineIndex == len(r.statement.queries)-1 {
 			r.finishedConsume = true
@@ -282,7 +612,7 @@ func (r *boltRows) NextPipeline() ([]interface{}, map[string]interface{}, Pipeli
 		return nil, success.Metadata, r.statement.rows, nil
 
 	case messages.RecordMessage:
-		log.Infof("Got record message: %#v", resp)
+		log.Infof("[%s] Got record message: %#v", r.statement.conn.ID(), resp)
 		return resp.Fields, nil, nil, nil
 	default:
 		return nil, nil, nil, errors.New("Unrecognized response type getting next pipeline row: %#v", resp)
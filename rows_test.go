@@ -0,0 +1,468 @@
+package golangNeo4jBoltDriver
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/encoding"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/messages"
+)
+
+func newFakeRows(t testing.TB, msgs ...interface{}) *boltRows {
+	return newFakeRowsWithFields(t, []interface{}{"1"}, msgs...)
+}
+
+func newFakeRowsWithFields(t testing.TB, fields []interface{}, msgs ...interface{}) *boltRows {
+	c := createBoltConn("")
+	c.conn = newFakeConn(t, msgs...)
+	c.statement = newStmt("RETURN 1", c)
+	c.statement.rows = newQueryRows(c.statement, map[string]interface{}{"fields": fields})
+	return c.statement.rows
+}
+
+func TestBoltRows_ForEach(t *testing.T) {
+	rows := newFakeRows(t,
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewRecordMessage([]interface{}{int64(2)}),
+		messages.NewRecordMessage([]interface{}{int64(3)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	var sum int64
+	err := rows.ForEach(func(row []interface{}) error {
+		sum += row[0].(int64)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("An error occurred iterating rows: %s", err)
+	}
+	if sum != 6 {
+		t.Fatalf("Unexpected sum of rows. Expected 6. Got: %d", sum)
+	}
+}
+
+func TestBoltRows_ForEachCallbackError(t *testing.T) {
+	rows := newFakeRows(t,
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewRecordMessage([]interface{}{int64(2)}),
+		messages.NewRecordMessage([]interface{}{int64(3)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	stop := errors.New("stop")
+	var calls int
+	err := rows.ForEach(func(row []interface{}) error {
+		calls++
+		if row[0].(int64) == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("Expected callback error to be returned. Got: %#v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("Expected iteration to stop after 2 rows. Got: %d", calls)
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatalf("An error occurred closing rows: %s", err)
+	}
+}
+
+func TestBoltRows_ForEachStreamFailure(t *testing.T) {
+	rows := newFakeRows(t,
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewFailureMessage(map[string]interface{}{"code": "Neo.ClientError.Statement.ArithmeticError", "message": "/ by zero"}),
+		messages.NewSuccessMessage(map[string]interface{}{}),
+	)
+
+	var calls int
+	err := rows.ForEach(func(row []interface{}) error {
+		calls++
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected stream failure to be returned")
+	}
+	if calls != 1 {
+		t.Fatalf("Expected 1 row before failure. Got: %d", calls)
+	}
+}
+
+func TestBoltRows_Peek(t *testing.T) {
+	rows := newFakeRows(t,
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewRecordMessage([]interface{}{int64(2)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	peeked, ok, err := rows.Peek()
+	if err != nil || !ok {
+		t.Fatalf("An error occurred peeking row: %v %s", ok, err)
+	}
+	if peeked[0] != int64(1) {
+		t.Fatalf("Unexpected peeked row: %#v", peeked)
+	}
+
+	// Peeking again doesn't advance
+	peeked, ok, err = rows.Peek()
+	if err != nil || !ok || peeked[0] != int64(1) {
+		t.Fatalf("Expected second peek to return the same row. Got: %#v %v %s", peeked, ok, err)
+	}
+
+	data, metadata, err := rows.All()
+	if err != nil {
+		t.Fatalf("An error occurred reading rows after peek: %s", err)
+	}
+	if !reflect.DeepEqual(data, [][]interface{}{{int64(1)}, {int64(2)}}) {
+		t.Fatalf("Expected peeked row to still be returned. Got: %#v", data)
+	}
+	if metadata["type"] != "r" {
+		t.Fatalf("Unexpected metadata after peek: %#v", metadata)
+	}
+}
+
+func TestBoltRows_PeekEOF(t *testing.T) {
+	rows := newFakeRows(t,
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	_, ok, err := rows.Peek()
+	if err != nil {
+		t.Fatalf("An error occurred peeking empty rows: %s", err)
+	}
+	if ok {
+		t.Fatal("Expected no row when peeking at end of stream")
+	}
+
+	_, metadata, err := rows.NextNeo()
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF after peeking end of stream. Got: %#v", err)
+	}
+	if metadata["type"] != "r" {
+		t.Fatalf("Unexpected metadata after peeking end of stream: %#v", metadata)
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatalf("An error occurred closing rows: %s", err)
+	}
+}
+
+func TestBoltRows_PeekFailure(t *testing.T) {
+	rows := newFakeRows(t,
+		messages.NewFailureMessage(map[string]interface{}{"code": "Neo.ClientError.Statement.ArithmeticError", "message": "/ by zero"}),
+		messages.NewSuccessMessage(map[string]interface{}{}),
+	)
+
+	_, ok, err := rows.Peek()
+	if err == nil {
+		t.Fatal("Expected stream failure to be returned from peek")
+	}
+	if ok {
+		t.Fatal("Expected no row when peek fails")
+	}
+}
+
+func TestBoltRows_RunMetadata(t *testing.T) {
+	encoded, err := encoding.Marshal(messages.NewSuccessMessage(map[string]interface{}{
+		"fields":                 []interface{}{"a", "b"},
+		"qid":                    int64(3),
+		"result_available_after": int64(15),
+	}))
+	if err != nil {
+		t.Fatalf("An error occurred encoding run success: %s", err)
+	}
+	decoded, err := encoding.Unmarshal(encoded)
+	if err != nil {
+		t.Fatalf("An error occurred decoding run success: %s", err)
+	}
+
+	runMetadata := newRunMetadata(decoded.(messages.SuccessMessage).Metadata)
+	expected := RunMetadata{
+		Fields:         []string{"a", "b"},
+		QID:            3,
+		AvailableAfter: 15 * time.Millisecond,
+	}
+	if !reflect.DeepEqual(runMetadata, expected) {
+		t.Fatalf("Unexpected run metadata. Expected %#v. Got: %#v", expected, runMetadata)
+	}
+
+	for _, key := range []string{"result_available_after", "t_first"} {
+		encoded, err := encoding.Marshal(messages.NewSuccessMessage(map[string]interface{}{key: int64(15)}))
+		if err != nil {
+			t.Fatalf("An error occurred encoding run success: %s", err)
+		}
+		decoded, err := encoding.Unmarshal(encoded)
+		if err != nil {
+			t.Fatalf("An error occurred decoding run success: %s", err)
+		}
+
+		runMetadata := newRunMetadata(decoded.(messages.SuccessMessage).Metadata)
+		if runMetadata.AvailableAfter != 15*time.Millisecond {
+			t.Fatalf("Unexpected available after from %s: %v", key, runMetadata.AvailableAfter)
+		}
+	}
+
+	runMetadata = newRunMetadata(map[string]interface{}{"fields": []interface{}{"a"}})
+	if runMetadata.QID != -1 {
+		t.Fatalf("Expected QID of -1 when missing from run success. Got: %d", runMetadata.QID)
+	}
+}
+
+func TestBoltRows_Columns(t *testing.T) {
+	rows := newFakeRowsWithFields(t, []interface{}{"a", "b"})
+
+	columns := rows.Columns()
+	if !reflect.DeepEqual(columns, []string{"a", "b"}) || !reflect.DeepEqual(rows.Columns(), columns) {
+		t.Fatalf("Expected repeated calls to return the same columns. Got: %#v", columns)
+	}
+
+	columns[0] = "changed"
+	if !reflect.DeepEqual(rows.Columns(), []string{"a", "b"}) {
+		t.Fatalf("Expected modifying the returned columns not to change them. Got: %#v", rows.Columns())
+	}
+}
+
+func TestBoltRows_ColumnValues(t *testing.T) {
+	rows := newFakeRowsWithFields(t, []interface{}{"name", "age"},
+		messages.NewRecordMessage([]interface{}{"a", int64(1)}),
+		messages.NewRecordMessage([]interface{}{"b", int64(2)}),
+		messages.NewRecordMessage([]interface{}{"c", int64(3)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	values, err := rows.ColumnValues("age")
+	if err != nil {
+		t.Fatalf("An error occurred getting column values: %s", err)
+	}
+	if !reflect.DeepEqual(values, []interface{}{int64(1), int64(2), int64(3)}) {
+		t.Fatalf("Unexpected column values: %#v", values)
+	}
+}
+
+func TestBoltRows_ColumnValuesUnknownColumn(t *testing.T) {
+	rows := newFakeRowsWithFields(t, []interface{}{"name", "age"},
+		messages.NewRecordMessage([]interface{}{"a", int64(1)}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	_, err := rows.ColumnValues("height")
+	if err == nil {
+		t.Fatal("Expected error getting values of unknown column")
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatalf("An error occurred closing rows: %s", err)
+	}
+}
+
+func TestBoltRows_ServerClosedMidPull(t *testing.T) {
+	// The fake conn hits EOF after the second record
+	rows := newFakeRows(t,
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewRecordMessage([]interface{}{int64(2)}),
+	)
+
+	output, _, err := rows.All()
+	if !reflect.DeepEqual(output, [][]interface{}{{int64(1)}, {int64(2)}}) {
+		t.Fatalf("Expected the records received before the close. Got: %#v", output)
+	}
+	if errors.Cause(err) != io.ErrUnexpectedEOF {
+		t.Fatalf("Expected wrapped unexpected EOF. Got: %#v", err)
+	}
+
+	conn := rows.statement.conn
+	if !conn.broken {
+		t.Fatal("Expected conn to be marked unusable")
+	}
+	if _, err := conn.QueryNeo("RETURN 1", nil); err == nil {
+		t.Fatal("Expected error querying on an unusable conn")
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("An error occurred closing unusable conn: %s", err)
+	}
+}
+
+func TestBoltRows_Graph(t *testing.T) {
+	a := graph.Node{NodeIdentity: 1, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "a"}}
+	b := graph.Node{NodeIdentity: 2, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "b"}}
+	c := graph.Node{NodeIdentity: 3, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "c"}}
+	knows := graph.UnboundRelationship{RelIdentity: 10, Type: "KNOWS", Properties: map[string]interface{}{}}
+	likes := graph.UnboundRelationship{RelIdentity: 11, Type: "LIKES", Properties: map[string]interface{}{}}
+
+	// (a)-[:KNOWS]->(b)<-[:LIKES]-(c), and (a)-[:KNOWS]->(b) again
+	path1 := graph.Path{Nodes: []graph.Node{a, b, c}, Relationships: []graph.UnboundRelationship{knows, likes}, Sequence: []int{1, 1, -2, 2}}
+	path2 := graph.Path{Nodes: []graph.Node{a, b}, Relationships: []graph.UnboundRelationship{knows}, Sequence: []int{1, 1}}
+	rows := newFakeRowsWithFields(t, []interface{}{"p", "n"},
+		messages.NewRecordMessage([]interface{}{path1, a}),
+		messages.NewRecordMessage([]interface{}{path2, []interface{}{c, nil}}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	g, err := rows.Graph()
+	if err != nil {
+		t.Fatalf("An error occurred getting graph: %s", err)
+	}
+
+	expectedNodes := map[int64]graph.Node{1: a, 2: b, 3: c}
+	if !reflect.DeepEqual(g.Nodes, expectedNodes) {
+		t.Fatalf("Unexpected graph nodes. Expected %#v. Got: %#v", expectedNodes, g.Nodes)
+	}
+	expectedRels := map[int64]graph.Relationship{
+		10: {RelIdentity: 10, StartNodeIdentity: 1, EndNodeIdentity: 2, Type: "KNOWS", Properties: map[string]interface{}{}},
+		11: {RelIdentity: 11, StartNodeIdentity: 3, EndNodeIdentity: 2, Type: "LIKES", Properties: map[string]interface{}{}},
+	}
+	if !reflect.DeepEqual(g.Rels, expectedRels) {
+		t.Fatalf("Unexpected graph relationships. Expected %#v. Got: %#v", expectedRels, g.Rels)
+	}
+}
+
+func TestBoltRows_WriteCSV(t *testing.T) {
+	rows := newFakeRowsWithFields(t, []interface{}{"name", "age", "n"},
+		messages.NewRecordMessage([]interface{}{"a, b", int64(1), graph.Node{NodeIdentity: 1, Labels: []string{"Person"}, Properties: map[string]interface{}{}}}),
+		messages.NewRecordMessage([]interface{}{"c", 2.5, nil}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	output := &bytes.Buffer{}
+	count, err := rows.WriteCSV(output, CSVOptions{})
+	if err != nil {
+		t.Fatalf("An error occurred writing CSV: %s", err)
+	}
+	if count != 2 {
+		t.Fatalf("Expected 2 rows written. Got: %d", count)
+	}
+
+	expected := "name,age,n\n" +
+		`"a, b",1,"{""id"":1,""labels"":[""Person""],""props"":{}}"` + "\n" +
+		"c,2.5,\n"
+	if output.String() != expected {
+		t.Fatalf("Unexpected CSV. Expected:\n%s\nGot:\n%s", expected, output.String())
+	}
+}
+
+func TestBoltRows_WriteCSVFlushesOnError(t *testing.T) {
+	rows := newFakeRowsWithFields(t, []interface{}{"val"},
+		messages.NewRecordMessage([]interface{}{"a"}),
+		// NaN can't be converted to JSON
+		messages.NewRecordMessage([]interface{}{[]interface{}{math.NaN()}}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	output := &bytes.Buffer{}
+	count, err := rows.WriteCSV(output, CSVOptions{})
+	if err == nil {
+		t.Fatal("Expected error writing a value that can't be converted to JSON")
+	}
+	if count != 1 || output.String() != "val\na\n" {
+		t.Fatalf("Expected the rows before the error to be flushed. Got %d rows: %q", count, output.String())
+	}
+}
+
+func TestBoltRows_WriteCSVOptions(t *testing.T) {
+	rows := newFakeRowsWithFields(t, []interface{}{"name", "active"},
+		messages.NewRecordMessage([]interface{}{"a", true}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	output := &bytes.Buffer{}
+	if _, err := rows.WriteCSV(output, CSVOptions{Delimiter: '\t', NoHeader: true}); err != nil {
+		t.Fatalf("An error occurred writing TSV: %s", err)
+	}
+	if output.String() != "a\ttrue\n" {
+		t.Fatalf("Unexpected TSV: %q", output.String())
+	}
+}
+
+func TestBoltRows_NextInto(t *testing.T) {
+	rows := newFakeRowsWithFields(t, []interface{}{"n", "name"},
+		messages.NewRecordMessage([]interface{}{int64(1), "a"}),
+		messages.NewRecordMessage([]interface{}{int64(2), "b"}),
+		messages.NewRecordMessage([]interface{}{int64(3), "c"}),
+		messages.NewSuccessMessage(map[string]interface{}{"type": "r"}),
+	)
+
+	// The peeked row is buffered, and copied into the buffer
+	if _, _, err := rows.Peek(); err != nil {
+		t.Fatalf("An error occurred peeking row: %s", err)
+	}
+
+	buf := make([]interface{}, 0, 2)
+	expected := [][]interface{}{{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}}
+	for _, exp := range expected {
+		row, err := rows.NextInto(buf)
+		if err != nil {
+			t.Fatalf("An error occurred getting next row: %s", err)
+		}
+		if !reflect.DeepEqual(row, exp) {
+			t.Fatalf("Unexpected row. Expected: %#v. Got: %#v", exp, row)
+		}
+		if &row[0] != &buf[:1][0] {
+			t.Fatal("Expected row to reuse the buffer")
+		}
+	}
+
+	if _, err := rows.NextInto(buf); err != io.EOF {
+		t.Fatalf("Expected EOF at the end of the rows. Got: %v", err)
+	}
+	if _, metadata, err := rows.NextNeo(); err != io.EOF || metadata["type"] != "r" {
+		t.Fatalf("Expected success metadata with EOF after NextInto. Got: %#v %v", metadata, err)
+	}
+}
+
+func TestBoltRows_NextIntoGrows(t *testing.T) {
+	rows := newFakeRowsWithFields(t, []interface{}{"a", "b", "c"},
+		messages.NewRecordMessage([]interface{}{int64(1), int64(2), int64(3)}),
+		messages.NewSuccessMessage(map[string]interface{}{}),
+	)
+
+	row, err := rows.NextInto(make([]interface{}, 1))
+	if err != nil {
+		t.Fatalf("An error occurred getting next row: %s", err)
+	}
+	if !reflect.DeepEqual(row, []interface{}{int64(1), int64(2), int64(3)}) {
+		t.Fatalf("Unexpected row: %#v", row)
+	}
+}
+
+func newBenchRows(b *testing.B) *boltRows {
+	msgs := make([]interface{}, 0, b.N+1)
+	for i := 0; i < b.N; i++ {
+		msgs = append(msgs, messages.NewRecordMessage([]interface{}{int64(1), "a", true}))
+	}
+	msgs = append(msgs, messages.NewSuccessMessage(map[string]interface{}{}))
+	return newFakeRowsWithFields(b, []interface{}{"n", "name", "flag"}, msgs...)
+}
+
+func BenchmarkBoltRows_NextNeo(b *testing.B) {
+	rows := newBenchRows(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, _, err := rows.NextNeo(); err != nil {
+			b.Fatalf("An error occurred getting next row: %s", err)
+		}
+	}
+}
+
+func BenchmarkBoltRows_NextInto(b *testing.B) {
+	rows := newBenchRows(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var row []interface{}
+	var err error
+	for i := 0; i < b.N; i++ {
+		if row, err = rows.NextInto(row); err != nil {
+			b.Fatalf("An error occurred getting next row: %s", err)
+		}
+	}
+}
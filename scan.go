@@ -0,0 +1,147 @@
+package golangNeo4jBoltDriver
+
+import (
+	"database/sql"
+	"math"
+	"reflect"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+)
+
+// Scanner copies the values of a row into destinations
+type Scanner struct {
+	// IgnoreExtraColumns skips the columns of a row after the last
+	// destination.  Defaults to erroring when the number of destinations
+	// doesn't match the number of columns.
+	IgnoreExtraColumns bool
+}
+
+// Next gets the next row from rows, and scans it into dest.
+// When the rows are completed, returns io.EOF.
+func (s Scanner) Next(rows Rows, dest ...interface{}) error {
+	row, _, err := rows.NextNeo()
+	if err != nil {
+		return err
+	}
+	return s.Scan(row, dest...)
+}
+
+// Scan copies each value in row into the destination at the same index,
+// each of which must be a pointer to a type the value is assignable to,
+// or a numeric type for a numeric value.  A destination implementing
+// sql.Scanner, such as *Money, scans the value itself.
+// A nil value sets the destination to its zero value.
+func (s Scanner) Scan(row []interface{}, dest ...interface{}) error {
+	if len(dest) > len(row) || (len(dest) < len(row) && !s.IgnoreExtraColumns) {
+		return errors.New("Expected %d destinations to scan the row's columns into. Got: %d", len(row), len(dest))
+	}
+
+	for i, d := range dest {
+		if scanner, ok := d.(sql.Scanner); ok {
+			if err := scanner.Scan(row[i]); err != nil {
+				return errors.Wrap(err, "An error occurred scanning column %d", i)
+			}
+			continue
+		}
+
+		destVal := reflect.ValueOf(d)
+		if destVal.Kind() != reflect.Ptr || destVal.IsNil() {
+			return errors.New("Scan destination %d must be a non-nil pointer. Got: %T", i, d)
+		}
+		destVal = destVal.Elem()
+
+		if row[i] == nil {
+			destVal.Set(reflect.Zero(destVal.Type()))
+			continue
+		}
+
+		val := reflect.ValueOf(row[i])
+		if val.Type().AssignableTo(destVal.Type()) {
+			destVal.Set(val)
+		} else if converted, ok := convertNumber(val, destVal.Type()); ok {
+			destVal.Set(converted)
+		} else {
+			return errors.New("Cannot scan column %d of type %T into %s", i, row[i], destVal.Type())
+		}
+	}
+
+	return nil
+}
+
+// convertNumber converts between numeric types, such as an int64 from the
+// server to an int.  Numbers that would overflow the type aren't converted,
+// nor are floats with a fractional part, NaN or infinity into integers.
+func convertNumber(val reflect.Value, to reflect.Type) (reflect.Value, bool) {
+	if !isNumberKind(val.Kind()) || !isNumberKind(to.Kind()) {
+		return reflect.Value{}, false
+	}
+
+	if !isIntKind(val.Kind()) && isIntKind(to.Kind()) {
+		return convertFloatToInt(val.Float(), to)
+	}
+
+	if !isIntKind(val.Kind()) && !isIntKind(to.Kind()) && reflect.Zero(to).OverflowFloat(val.Float()) {
+		return reflect.Value{}, false
+	}
+
+	if isIntKind(val.Kind()) && isIntKind(to.Kind()) {
+		zero := reflect.Zero(to)
+		if isSignedKind(val.Kind()) {
+			i := val.Int()
+			if (isSignedKind(to.Kind()) && zero.OverflowInt(i)) ||
+				(!isSignedKind(to.Kind()) && (i < 0 || zero.OverflowUint(uint64(i)))) {
+				return reflect.Value{}, false
+			}
+		} else {
+			u := val.Uint()
+			if (isSignedKind(to.Kind()) && (u > math.MaxInt64 || zero.OverflowInt(int64(u)))) ||
+				(!isSignedKind(to.Kind()) && zero.OverflowUint(u)) {
+				return reflect.Value{}, false
+			}
+		}
+	}
+
+	return val.Convert(to), true
+}
+
+// convertFloatToInt converts a float to an integer type, if it's a whole
+// number in the range of the type
+func convertFloatToInt(f float64, to reflect.Type) (reflect.Value, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+		return reflect.Value{}, false
+	}
+
+	zero := reflect.Zero(to)
+	if isSignedKind(to.Kind()) {
+		// -2^63 converts exactly, but 2^63 is already out of range
+		if f < math.MinInt64 || f >= -math.MinInt64 || zero.OverflowInt(int64(f)) {
+			return reflect.Value{}, false
+		}
+		return reflect.ValueOf(int64(f)).Convert(to), true
+	}
+
+	if f < 0 || f >= 2*-math.MinInt64 || zero.OverflowUint(uint64(f)) {
+		return reflect.Value{}, false
+	}
+	return reflect.ValueOf(uint64(f)).Convert(to), true
+}
+
+func isSignedKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return isSignedKind(kind)
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return isIntKind(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
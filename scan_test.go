@@ -0,0 +1,113 @@
+package golangNeo4jBoltDriver
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/messages"
+)
+
+func TestScanner_Scan(t *testing.T) {
+	var name string
+	var age int64
+	var extra interface{}
+	if err := (Scanner{}).Scan([]interface{}{"alice", int64(30), nil}, &name, &age, &extra); err != nil {
+		t.Fatalf("An error occurred scanning row: %s", err)
+	}
+	if name != "alice" || age != 30 || extra != nil {
+		t.Fatalf("Unexpected scanned values: %s %d %#v", name, age, extra)
+	}
+
+	if err := (Scanner{}).Scan([]interface{}{"alice"}, &age); err == nil {
+		t.Fatal("Expected error scanning a string into an int64")
+	}
+	if err := (Scanner{}).Scan([]interface{}{"alice"}, name); err == nil {
+		t.Fatal("Expected error scanning into a non-pointer")
+	}
+}
+
+func TestScanner_ExtraColumns(t *testing.T) {
+	var name string
+	row := []interface{}{"alice", int64(30)}
+
+	if err := (Scanner{}).Scan(row, &name); err == nil {
+		t.Fatal("Expected error scanning a row with more columns than destinations")
+	}
+
+	if err := (Scanner{IgnoreExtraColumns: true}).Scan(row, &name); err != nil {
+		t.Fatalf("An error occurred scanning row ignoring extra columns: %s", err)
+	}
+	if name != "alice" {
+		t.Fatalf("Unexpected scanned name: %s", name)
+	}
+
+	// Missing columns are always an error
+	var age, other int64
+	if err := (Scanner{IgnoreExtraColumns: true}).Scan(row, &name, &age, &other); err == nil {
+		t.Fatal("Expected error scanning a row with fewer columns than destinations")
+	}
+}
+
+func TestScanner_Next(t *testing.T) {
+	rows := newFakeRows(t,
+		messages.NewRecordMessage([]interface{}{int64(1)}),
+		messages.NewSuccessMessage(map[string]interface{}{}),
+	)
+
+	var n int64
+	if err := (Scanner{}).Next(rows, &n); err != nil {
+		t.Fatalf("An error occurred scanning next row: %s", err)
+	}
+	if n != 1 {
+		t.Fatalf("Unexpected scanned value: %d", n)
+	}
+	if err := (Scanner{}).Next(rows, &n); err != io.EOF {
+		t.Fatalf("Expected EOF at the end of the rows. Got: %v", err)
+	}
+}
+
+func TestScanner_ScanConversion(t *testing.T) {
+	var i int
+	var f float64
+	var u8 uint8
+	if err := (Scanner{}).Scan([]interface{}{int64(5), int64(2), int64(200)}, &i, &f, &u8); err != nil {
+		t.Fatalf("An error occurred scanning with conversion: %s", err)
+	}
+	if i != 5 || f != 2 || u8 != 200 {
+		t.Fatalf("Unexpected converted values: %d %f %d", i, f, u8)
+	}
+
+	for _, val := range []interface{}{int64(300), int64(-1)} {
+		if err := (Scanner{}).Scan([]interface{}{val}, &u8); err == nil {
+			t.Fatalf("Expected error scanning %d into a uint8", val)
+		}
+	}
+}
+
+func TestScanner_ScanFloatToInt(t *testing.T) {
+	var i int64
+	var u8 uint8
+	if err := (Scanner{}).Scan([]interface{}{3.0, 200.0}, &i, &u8); err != nil {
+		t.Fatalf("An error occurred scanning whole floats into integers: %s", err)
+	}
+	if i != 3 || u8 != 200 {
+		t.Fatalf("Unexpected converted values: %d %d", i, u8)
+	}
+
+	for _, val := range []float64{3.7, -0.5, math.NaN(), math.Inf(1), math.Inf(-1), 1e19, -1e19} {
+		if err := (Scanner{}).Scan([]interface{}{val}, &i); err == nil {
+			t.Fatalf("Expected error scanning %v into an int64. Got: %d", val, i)
+		}
+	}
+	for _, val := range []float64{256, -1} {
+		if err := (Scanner{}).Scan([]interface{}{val}, &u8); err == nil {
+			t.Fatalf("Expected error scanning %v into a uint8. Got: %d", val, u8)
+		}
+	}
+
+	var f32 float32
+	if err := (Scanner{}).Scan([]interface{}{1e39}, &f32); err == nil {
+		t.Fatalf("Expected error scanning a float too big for a float32. Got: %v", f32)
+	}
+}
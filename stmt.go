@@ -2,6 +2,7 @@ package golangNeo4jBoltDriver
 
 import (
 	"database/sql/driver"
+	"time"
 
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
 	"github.com/johnnadratowski/golang-neo4j-bolt-driver/log"
@@ -96,6 +97,7 @@ func (s *boltStmt) ExecNeo(params map[string]interface{}) (Result, error) {
 		return nil, errors.New("Another query is already open")
 	}
 
+	start := time.Now()
 	runResp, pullResp, _, err := s.conn.sendRunPullAllConsumeAll(s.query, params)
 	if err != nil {
 		return nil, err
@@ -107,16 +109,16 @@ func (s *boltStmt) ExecNeo(params map[string]interface{}) (Result, error) {
 
 	}
 
-	log.Infof("Got run success message: %#v", success)
+	log.Infof("[%s] Got run success message: %#v", s.conn.ID(), success)
 
 	success, ok = pullResp.(messages.SuccessMessage)
 	if !ok {
 		return nil, errors.New("Unrecognized response when discarding exec rows: %#v", success)
 	}
 
-	log.Infof("Got discard all success message: %#v", success)
+	log.Infof("[%s] Got discard all success message: %#v", s.conn.ID(), success)
 
-	return newResult(success.Metadata), nil
+	return newResult(success.Metadata, time.Since(start)), nil
 }
 
 func (s *boltStmt) ExecPipeline(params ...map[string]interface{}) ([]Result, error) {
@@ -131,14 +133,15 @@ func (s *boltStmt) ExecPipeline(params ...map[string]interface{}) ([]Result, err
 		return nil, errors.New("Must pass same number of params as there are queries")
 	}
 
+	start := time.Now()
 	for i, query := range s.queries {
 		err := s.conn.sendRunPullAll(query, params[i])
 		if err != nil {
-			return nil, errors.Wrap(err, "Error running exec query:\n\n%s\n\nWith Params:\n%#v", query, params[i])
+			return nil, errors.Wrap(err, "Error running exec query:\n\n%s\n\nWith Params:\n%#v", query, redactParams(params[i]))
 		}
 	}
 
-	log.Info("Successfully ran all pipeline queries")
+	log.Infof("[%s] Successfully ran all pipeline queries", s.conn.ID())
 
 	results := make([]Result, len(s.queries))
 	for i := range s.queries {
@@ -162,7 +165,7 @@ func (s *boltStmt) ExecPipeline(params ...map[string]interface{}) ([]Result, err
 			return nil, errors.New("Unexpected response when getting exec query discard result: %#v", pullResp)
 		}
 
-		results[i] = newResult(success.Metadata)
+		results[i] = newResult(success.Metadata, time.Since(start))
 
 	}
 
@@ -202,7 +205,7 @@ func (s *boltStmt) queryNeo(params map[string]interface{}) (*boltRows, error) {
 		return nil, errors.New("Unrecognized response type running query: %#v", resp)
 	}
 
-	log.Infof("Got success message on run query: %#v", resp)
+	log.Infof("[%s] Got success message on run query: %#v", s.conn.ID(), resp)
 	s.rows = newRows(s, resp.Metadata)
 	return s.rows, nil
 }
@@ -222,11 +225,11 @@ func (s *boltStmt) QueryPipeline(params ...map[string]interface{}) (PipelineRows
 	for i, query := range s.queries {
 		err := s.conn.sendRunPullAll(query, params[i])
 		if err != nil {
-			return nil, errors.Wrap(err, "Error running query:\n\n%s\n\nWith Params:\n%#v", query, params[i])
+			return nil, errors.Wrap(err, "Error running query:\n\n%s\n\nWith Params:\n%#v", query, redactParams(params[i]))
 		}
 	}
 
-	log.Info("Successfully ran all pipeline queries")
+	log.Infof("[%s] Successfully ran all pipeline queries", s.conn.ID())
 
 	resp, err := s.conn.consume()
 	if err != nil {
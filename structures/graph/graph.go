@@ -0,0 +1,74 @@
+package graph
+
+// Graph is a subgraph of nodes and relationships, keyed by their identity
+type Graph struct {
+	Nodes map[int64]Node
+	Rels  map[int64]Relationship
+}
+
+// NewGraph creates a new, empty Graph
+func NewGraph() Graph {
+	return Graph{
+		Nodes: map[int64]Node{},
+		Rels:  map[int64]Relationship{},
+	}
+}
+
+// Add adds the nodes and relationships in val to the graph.  val can be a
+// Node, Relationship or Path, or a list or map containing them.  Other
+// values are ignored.
+func (g Graph) Add(val interface{}) {
+	switch v := val.(type) {
+	case Node:
+		g.Nodes[v.NodeIdentity] = v
+	case Relationship:
+		g.Rels[v.RelIdentity] = v
+	case Path:
+		g.addPath(v)
+	case []interface{}:
+		for _, item := range v {
+			g.Add(item)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			g.Add(item)
+		}
+	}
+}
+
+// addPath adds the nodes in the path, and its relationships bound to the
+// nodes they connect by following the sequence
+func (g Graph) addPath(p Path) {
+	for _, node := range p.Nodes {
+		g.Add(node)
+	}
+	if len(p.Nodes) == 0 {
+		return
+	}
+
+	prev := p.Nodes[0]
+	for i := 0; i+1 < len(p.Sequence); i += 2 {
+		relIdx, nodeIdx := p.Sequence[i], p.Sequence[i+1]
+		if relIdx == 0 || relIdx > len(p.Relationships) || -relIdx > len(p.Relationships) ||
+			nodeIdx < 0 || nodeIdx >= len(p.Nodes) {
+			return
+		}
+
+		next := p.Nodes[nodeIdx]
+		start, end := prev, next
+		if relIdx < 0 {
+			start, end = next, prev
+			relIdx = -relIdx
+		}
+
+		rel := p.Relationships[relIdx-1]
+		g.Add(Relationship{
+			RelIdentity:       rel.RelIdentity,
+			StartNodeIdentity: start.NodeIdentity,
+			EndNodeIdentity:   end.NodeIdentity,
+			Type:              rel.Type,
+			Properties:        rel.Properties,
+		})
+		prev = next
+	}
+}
@@ -6,6 +6,7 @@ const (
 )
 
 // Node Represents a Node structure
+// Labels are kept in the order they were sent by the server
 type Node struct {
 	NodeIdentity int64
 	Labels       []string
@@ -25,3 +26,29 @@ func (n Node) AllFields() []interface{} {
 	}
 	return []interface{}{n.NodeIdentity, labels, n.Properties}
 }
+
+// GetString gets a string property of the node
+func (n Node) GetString(key string) (string, error) {
+	return getString(n.Properties, key)
+}
+
+// GetInt gets an integer property of the node
+func (n Node) GetInt(key string) (int64, error) {
+	return getInt(n.Properties, key)
+}
+
+// GetFloat gets a float property of the node. Integer properties are converted.
+func (n Node) GetFloat(key string) (float64, error) {
+	return getFloat(n.Properties, key)
+}
+
+// GetBool gets a boolean property of the node
+func (n Node) GetBool(key string) (bool, error) {
+	return getBool(n.Properties, key)
+}
+
+// PropertiesAs fills the struct pointed to by dest from the node's properties,
+// matching them to fields the same way as encoding/json, including json tags
+func (n Node) PropertiesAs(dest interface{}) error {
+	return propertiesAs(n.Properties, dest)
+}
@@ -1,5 +1,7 @@
 package graph
 
+import "github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+
 const (
 	// PathSignature is the signature byte for a Path object
 	PathSignature = 0x50
@@ -12,6 +14,31 @@ type Path struct {
 	Sequence      []int
 }
 
+// NewPath creates a new Path, checking the sequence is valid.  The sequence
+// alternates between a relationship index and a node index for each hop from
+// the first node.  Relationship indexes start at 1, and are negative when the
+// relationship is traversed backwards.  Node indexes start at 0.
+func NewPath(nodes []Node, rels []UnboundRelationship, sequence []int) (Path, error) {
+	if len(nodes) == 0 {
+		return Path{}, errors.New("Path must have at least one node")
+	}
+	if len(sequence)%2 != 0 {
+		return Path{}, errors.New("Path sequence must have an even length. Got: %d", len(sequence))
+	}
+
+	for i := 0; i < len(sequence); i += 2 {
+		rel, node := sequence[i], sequence[i+1]
+		if rel == 0 || rel > len(rels) || -rel > len(rels) {
+			return Path{}, errors.New("Path sequence has invalid relationship index %d at %d. There are %d relationships", rel, i, len(rels))
+		}
+		if node < 0 || node >= len(nodes) {
+			return Path{}, errors.New("Path sequence has invalid node index %d at %d. There are %d nodes", node, i+1, len(nodes))
+		}
+	}
+
+	return Path{Nodes: nodes, Relationships: rels, Sequence: sequence}, nil
+}
+
 // Signature gets the signature byte for the struct
 func (p Path) Signature() int {
 	return PathSignature
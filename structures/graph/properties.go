@@ -0,0 +1,79 @@
+package graph
+
+import (
+	"encoding/json"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+)
+
+func getProperty(properties map[string]interface{}, key string) (interface{}, error) {
+	val, ok := properties[key]
+	if !ok {
+		return nil, errors.New("Property %s not found", key)
+	}
+	return val, nil
+}
+
+func getString(properties map[string]interface{}, key string) (string, error) {
+	val, err := getProperty(properties, key)
+	if err != nil {
+		return "", err
+	}
+	s, ok := val.(string)
+	if !ok {
+		return "", errors.New("Expected property %s to be a string, but got %T %+v", key, val, val)
+	}
+	return s, nil
+}
+
+func getInt(properties map[string]interface{}, key string) (int64, error) {
+	val, err := getProperty(properties, key)
+	if err != nil {
+		return 0, err
+	}
+	i, ok := val.(int64)
+	if !ok {
+		return 0, errors.New("Expected property %s to be an int64, but got %T %+v", key, val, val)
+	}
+	return i, nil
+}
+
+func getFloat(properties map[string]interface{}, key string) (float64, error) {
+	val, err := getProperty(properties, key)
+	if err != nil {
+		return 0, err
+	}
+	switch val := val.(type) {
+	case float64:
+		return val, nil
+	case int64:
+		return float64(val), nil
+	default:
+		return 0, errors.New("Expected property %s to be a float64, but got %T %+v", key, val, val)
+	}
+}
+
+func getBool(properties map[string]interface{}, key string) (bool, error) {
+	val, err := getProperty(properties, key)
+	if err != nil {
+		return false, err
+	}
+	b, ok := val.(bool)
+	if !ok {
+		return false, errors.New("Expected property %s to be a bool, but got %T %+v", key, val, val)
+	}
+	return b, nil
+}
+
+// propertiesAs fills dest from the properties, matching them to its fields
+// the same way as encoding/json
+func propertiesAs(properties map[string]interface{}, dest interface{}) error {
+	b, err := json.Marshal(properties)
+	if err != nil {
+		return errors.Wrap(err, "An error occurred converting properties")
+	}
+	if err := json.Unmarshal(b, dest); err != nil {
+		return errors.Wrap(err, "An error occurred converting properties to %T", dest)
+	}
+	return nil
+}
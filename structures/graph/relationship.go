@@ -23,3 +23,29 @@ func (r Relationship) Signature() int {
 func (r Relationship) AllFields() []interface{} {
 	return []interface{}{r.RelIdentity, r.StartNodeIdentity, r.EndNodeIdentity, r.Type, r.Properties}
 }
+
+// GetString gets a string property of the relationship
+func (r Relationship) GetString(key string) (string, error) {
+	return getString(r.Properties, key)
+}
+
+// GetInt gets an integer property of the relationship
+func (r Relationship) GetInt(key string) (int64, error) {
+	return getInt(r.Properties, key)
+}
+
+// GetFloat gets a float property of the relationship. Integer properties are converted.
+func (r Relationship) GetFloat(key string) (float64, error) {
+	return getFloat(r.Properties, key)
+}
+
+// GetBool gets a boolean property of the relationship
+func (r Relationship) GetBool(key string) (bool, error) {
+	return getBool(r.Properties, key)
+}
+
+// PropertiesAs fills the struct pointed to by dest from the relationship's properties,
+// matching them to fields the same way as encoding/json, including json tags
+func (r Relationship) PropertiesAs(dest interface{}) error {
+	return propertiesAs(r.Properties, dest)
+}
@@ -26,6 +26,11 @@ func NewInitMessage(clientName string, user string, password string) InitMessage
 		}
 	}
 
+	return NewInitMessageWithToken(clientName, authToken)
+}
+
+// NewInitMessageWithToken Gets a new InitMessage struct using the given auth token
+func NewInitMessageWithToken(clientName string, authToken map[string]interface{}) InitMessage {
 	return InitMessage{
 		clientName: clientName,
 		authToken:  authToken,
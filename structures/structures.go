@@ -1,7 +1,73 @@
 package structures
 
+import "reflect"
+
 // Structure represents a Neo4J structure
 type Structure interface {
 	Signature() int
 	AllFields() []interface{}
 }
+
+// GenericStructure represents a Neo4J structure with a signature
+// that isn't specifically modeled by the driver.  The raw fields are
+// kept so that the structure can be re-encoded exactly as it was received.
+type GenericStructure struct {
+	StructSignature int
+	Fields          []interface{}
+}
+
+// Signature gets the signature byte for the struct
+func (s GenericStructure) Signature() int {
+	return s.StructSignature
+}
+
+// AllFields gets the fields to encode for the struct
+func (s GenericStructure) AllFields() []interface{} {
+	return s.Fields
+}
+
+// Equal checks if two structures have the same signature and fields.
+// Nested structures, maps and slices in the fields are compared deeply,
+// and a nil map is considered equal to an empty one.
+func Equal(a, b Structure) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a.Signature() != b.Signature() {
+		return false
+	}
+	return equalValue(a.AllFields(), b.AllFields())
+}
+
+func equalValue(a, b interface{}) bool {
+	switch a := a.(type) {
+	case Structure:
+		b, ok := b.(Structure)
+		return ok && Equal(a, b)
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, aVal := range a {
+			bVal, ok := b[key]
+			if !ok || !equalValue(aVal, bVal) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValue(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
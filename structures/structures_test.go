@@ -0,0 +1,60 @@
+package structures
+
+import (
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/graph"
+)
+
+func TestEqual_Node(t *testing.T) {
+	a := graph.Node{
+		NodeIdentity: 1,
+		Labels:       []string{"Person"},
+		Properties:   map[string]interface{}{"name": "a", "tags": []interface{}{"x", "y"}},
+	}
+	b := graph.Node{
+		NodeIdentity: 1,
+		Labels:       []string{"Person"},
+		Properties:   map[string]interface{}{"name": "a", "tags": []interface{}{"x", "y"}},
+	}
+	if !Equal(a, b) {
+		t.Fatalf("Expected identical nodes to be equal. A: %#v B: %#v", a, b)
+	}
+
+	b.Properties["name"] = "b"
+	if Equal(a, b) {
+		t.Fatalf("Expected nodes differing in a property to not be equal. A: %#v B: %#v", a, b)
+	}
+}
+
+func TestEqual_Relationship(t *testing.T) {
+	a := graph.Relationship{RelIdentity: 3, StartNodeIdentity: 1, EndNodeIdentity: 2, Type: "KNOWS", Properties: map[string]interface{}{}}
+	b := graph.Relationship{RelIdentity: 3, StartNodeIdentity: 1, EndNodeIdentity: 2, Type: "KNOWS"}
+	if !Equal(a, b) {
+		t.Fatalf("Expected identical relationships to be equal. A: %#v B: %#v", a, b)
+	}
+
+	if Equal(a, graph.UnboundRelationship{RelIdentity: 3, Type: "KNOWS"}) {
+		t.Fatal("Expected structures with different signatures to not be equal")
+	}
+}
+
+func TestEqual_Path(t *testing.T) {
+	path := func(name string) graph.Path {
+		return graph.Path{
+			Nodes: []graph.Node{
+				{NodeIdentity: 1, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": "a"}},
+				{NodeIdentity: 2, Labels: []string{"Person"}, Properties: map[string]interface{}{"name": name}},
+			},
+			Relationships: []graph.UnboundRelationship{{RelIdentity: 3, Type: "KNOWS"}},
+			Sequence:      []int{1, 1},
+		}
+	}
+
+	if !Equal(path("b"), path("b")) {
+		t.Fatal("Expected identical paths to be equal")
+	}
+	if Equal(path("b"), path("c")) {
+		t.Fatal("Expected paths with differing nested node properties to not be equal")
+	}
+}
@@ -14,6 +14,20 @@ type Tx interface {
 	Rollback() error
 }
 
+// TxRunner runs queries in the transaction started by ExecuteRead or ExecuteWrite
+type TxRunner interface {
+	// QueryNeo queries using the neo4j-specific interface
+	QueryNeo(query string, params map[string]interface{}) (Rows, error)
+	// QueryNeoAll queries using the neo4j-specific interface and returns all row data and output metadata
+	QueryNeoAll(query string, params map[string]interface{}) ([][]interface{}, map[string]interface{}, map[string]interface{}, error)
+	// ExecNeo executes a query using the neo4j-specific interface
+	ExecNeo(query string, params map[string]interface{}) (Result, error)
+}
+
+// TxWork is the work done in a transaction by ExecuteRead or ExecuteWrite.
+// It may be run more than once if the transaction fails transiently.
+type TxWork func(tx TxRunner) (interface{}, error)
+
 type boltTx struct {
 	conn   *boltConn
 	closed bool
@@ -38,6 +52,9 @@ func (t *boltTx) Commit() error {
 
 	successInt, pullInt, err := t.conn.sendRunPullAllConsumeSingle("COMMIT", nil)
 	if err != nil {
+		// The server ends the transaction when a commit fails
+		t.closed = true
+		t.conn.endTransaction()
 		return errors.Wrap(err, "An error occurred committing transaction")
 	}
 
@@ -46,17 +63,17 @@ func (t *boltTx) Commit() error {
 		return errors.New("Unrecognized response type committing transaction: %#v", success)
 	}
 
-	log.Infof("Got success message committing transaction: %#v", success)
+	log.Infof("[%s] Got success message committing transaction: %#v", t.conn.ID(), success)
 
 	pull, ok := pullInt.(messages.SuccessMessage)
 	if !ok {
 		return errors.New("Unrecognized response type pulling transaction:  %#v", pull)
 	}
 
-	log.Infof("Got success message pulling transaction: %#v", pull)
+	log.Infof("[%s] Got success message pulling transaction: %#v", t.conn.ID(), pull)
 
-	t.conn.transaction = nil
 	t.closed = true
+	t.conn.endTransaction()
 	return err
 }
 
@@ -73,6 +90,8 @@ func (t *boltTx) Rollback() error {
 
 	successInt, pullInt, err := t.conn.sendRunPullAllConsumeSingle("ROLLBACK", nil)
 	if err != nil {
+		t.closed = true
+		t.conn.endTransaction()
 		return errors.Wrap(err, "An error occurred rolling back transaction")
 	}
 
@@ -81,16 +100,16 @@ func (t *boltTx) Rollback() error {
 		return errors.New("Unrecognized response type rolling back transaction: %#v", success)
 	}
 
-	log.Infof("Got success message rolling back transaction: %#v", success)
+	log.Infof("[%s] Got success message rolling back transaction: %#v", t.conn.ID(), success)
 
 	pull, ok := pullInt.(messages.SuccessMessage)
 	if !ok {
 		return errors.New("Unrecognized response type pulling transaction: %#v", pull)
 	}
 
-	log.Infof("Got success message pulling transaction: %#v", pull)
+	log.Infof("[%s] Got success message pulling transaction: %#v", t.conn.ID(), pull)
 
-	t.conn.transaction = nil
 	t.closed = true
+	t.conn.endTransaction()
 	return err
 }
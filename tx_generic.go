@@ -0,0 +1,29 @@
+//go:build go1.18
+
+package golangNeo4jBoltDriver
+
+import (
+	"context"
+)
+
+// ExecuteRead runs work in a read transaction on conn, like Conn.ExecuteRead,
+// returning the work's typed result without a type assertion
+func ExecuteRead[T any](ctx context.Context, conn Conn, work func(tx TxRunner) (T, error)) (T, error) {
+	return executeTyped(ctx, conn.ExecuteRead, work)
+}
+
+// ExecuteWrite runs work in a write transaction on conn, like Conn.ExecuteWrite,
+// returning the work's typed result without a type assertion
+func ExecuteWrite[T any](ctx context.Context, conn Conn, work func(tx TxRunner) (T, error)) (T, error) {
+	return executeTyped(ctx, conn.ExecuteWrite, work)
+}
+
+func executeTyped[T any](ctx context.Context, execute func(context.Context, TxWork) (interface{}, error), work func(tx TxRunner) (T, error)) (T, error) {
+	result, err := execute(ctx, func(tx TxRunner) (interface{}, error) {
+		return work(tx)
+	})
+	// A nil result, when T is an interface, doesn't assert to T, so the
+	// zero value is returned for it
+	typed, _ := result.(T)
+	return typed, err
+}
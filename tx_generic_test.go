@@ -0,0 +1,67 @@
+//go:build go1.18
+
+package golangNeo4jBoltDriver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/errors"
+	"github.com/johnnadratowski/golang-neo4j-bolt-driver/structures/messages"
+)
+
+type testPerson struct {
+	Name string
+	Age  int64
+}
+
+func TestExecuteRead_Typed(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	c := createBoltConn("")
+	c.conn = newFakeConn(t,
+		success, success,
+		messages.NewSuccessMessage(map[string]interface{}{"fields": []interface{}{"name", "age"}}),
+		messages.NewRecordMessage([]interface{}{"alice", int64(30)}),
+		messages.NewRecordMessage([]interface{}{"bob", int64(40)}),
+		success,
+		success, success,
+	)
+
+	people, err := ExecuteRead(context.Background(), c, func(tx TxRunner) ([]testPerson, error) {
+		data, _, _, err := tx.QueryNeoAll("MATCH (p:Person) RETURN p.name, p.age", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		people := make([]testPerson, len(data))
+		for i, row := range data {
+			people[i] = testPerson{Name: row[0].(string), Age: row[1].(int64)}
+		}
+		return people, nil
+	})
+	if err != nil {
+		t.Fatalf("An error occurred executing typed read: %s", err)
+	}
+
+	expected := []testPerson{{"alice", 30}, {"bob", 40}}
+	if !reflect.DeepEqual(people, expected) {
+		t.Fatalf("Unexpected people. Expected: %#v. Got: %#v", expected, people)
+	}
+}
+
+func TestExecuteWrite_TypedError(t *testing.T) {
+	success := messages.NewSuccessMessage(map[string]interface{}{})
+	c := createBoltConn("")
+	c.conn = newFakeConn(t, success, success, success, success)
+
+	count, err := ExecuteWrite(context.Background(), c, func(tx TxRunner) (int, error) {
+		return 5, errors.New("work failed")
+	})
+	if err == nil {
+		t.Fatal("Expected error from failed work")
+	}
+	if count != 0 {
+		t.Fatalf("Expected zero value on error. Got: %d", count)
+	}
+}
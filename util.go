@@ -27,6 +27,15 @@ func sprintByteHex(b []byte) string {
 	return output
 }
 
+// sprintByteHexLimit returns sprintByteHex of at most the first limit bytes,
+// so huge payloads don't flood the logs.  A limit of 0 or less dumps everything.
+func sprintByteHexLimit(b []byte, limit int) string {
+	if limit <= 0 || len(b) <= limit {
+		return sprintByteHex(b)
+	}
+	return sprintByteHex(b[:limit]) + fmt.Sprintf("\t... %d more bytes\n", len(b)-limit)
+}
+
 // driverArgsToMap turns driver.Value list into a parameter map
 // for neo4j parameters
 func driverArgsToMap(args []driver.Value) (map[string]interface{}, error) {